@@ -0,0 +1,276 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Annotation types used in brat standoff files.
+const (
+	BratChunkType    = "Bunsetsu" // text-bound annotation of a chunk
+	BratRelationType = "Dep"      // relation from a chunk to its head chunk
+)
+
+// BratAnnotationConf is an annotation.conf for brat collections holding
+// files written by ToBrat, declaring the IREX named entity types.
+const BratAnnotationConf = `[entities]
+Bunsetsu
+ARTIFACT
+DATE
+LOCATION
+MONEY
+ORGANIZATION
+PERCENT
+PERSON
+TIME
+OPTIONAL
+
+[relations]
+Dep	Arg1:Bunsetsu, Arg2:Bunsetsu
+<OVERLAP>	Arg1:<ENTITY>, Arg2:<ENTITY>, <OVL-TYPE>:<ANY>
+
+[events]
+
+[attributes]
+`
+
+// ToBrat returns s as a brat standoff pair: the text, and the annotations
+// of its chunks (as BratChunkType spans), their dependencies (as
+// BratRelationType relations) and its named entities (see Entities).
+// Offsets are counted from the first token.
+func (s *Sentence) ToBrat() (txt, ann string) {
+	tokens := s.Tokens()
+	if len(tokens) == 0 {
+		return "", ""
+	}
+	txt = surfaceText(tokens)
+	return txt, bratAnnotations(txt, []*Sentence{s}, tokens[0].Begin)
+}
+
+// ToBrat returns d as a brat standoff pair, as Sentence.ToBrat does, with
+// offsets into d.Text.
+func (d *Document) ToBrat() (txt, ann string) {
+	return d.Text, bratAnnotations(d.Text, d.Sentences, 0)
+}
+
+// Returns the annotations of sentences over txt, in which token offsets
+// are shifted by shift.
+func bratAnnotations(txt string, sentences []*Sentence, shift int) string {
+	text := []rune(txt)
+	var b strings.Builder
+	ids := map[*Chunk]int{}
+	n := 0
+	span := func(kind string, begin, end int) {
+		n++
+		begin, end = begin-shift, end-shift
+		covered := ""
+		if begin >= 0 && begin <= end && end <= len(text) {
+			covered = string(text[begin:end])
+		}
+		fmt.Fprintf(&b, "T%d\t%s %d %d\t%s\n", n, kind, begin, end, covered)
+	}
+
+	for _, s := range sentences {
+		for _, c := range s.Chunks {
+			if len(c.Tokens) > 0 {
+				span(BratChunkType, c.Tokens[0].Begin, c.Tokens[len(c.Tokens)-1].End)
+				ids[c] = n
+			}
+		}
+	}
+	for _, s := range sentences {
+		for _, e := range s.Entities() {
+			span(e.Type, e.Begin, e.End)
+		}
+	}
+	r := 0
+	for _, s := range sentences {
+		for _, c := range s.Chunks {
+			if p := c.Parent(); p != nil && ids[c] > 0 && ids[p] > 0 {
+				r++
+				fmt.Fprintf(&b, "R%d\t%s Arg1:T%d Arg2:T%d\n", r, BratRelationType, ids[c], ids[p])
+			}
+		}
+	}
+	return b.String()
+}
+
+// ImportBrat applies brat annotations, as written by ToBrat and corrected
+// in brat, to s: every chunk depends on the chunk its BratRelationType
+// relation points to (or is a root if it has none), and Token.Ne is
+// retagged from the named entity spans. Chunk spans must still match the
+// chunks of s. On error s is left unchanged.
+func (s *Sentence) ImportBrat(ann string) error {
+	shift := 0
+	if tokens := s.Tokens(); len(tokens) > 0 {
+		shift = tokens[0].Begin
+	}
+	return importBrat(ann, []*Sentence{s}, shift)
+}
+
+// ImportBrat applies brat annotations to the sentences of d, as
+// Sentence.ImportBrat does. Relations may not cross sentences.
+func (d *Document) ImportBrat(ann string) error {
+	return importBrat(ann, d.Sentences, 0)
+}
+
+// A text-bound annotation.
+type bratSpan struct {
+	kind       string
+	begin, end int
+}
+
+func importBrat(ann string, sentences []*Sentence, shift int) error {
+	type chunkSpan struct{ begin, end int }
+	chunks := map[chunkSpan]*Chunk{}
+	for _, s := range sentences {
+		for _, c := range s.Chunks {
+			if len(c.Tokens) > 0 {
+				chunks[chunkSpan{c.Tokens[0].Begin - shift, c.Tokens[len(c.Tokens)-1].End - shift}] = c
+			}
+		}
+	}
+
+	spans := map[string]*bratSpan{}
+	var entities []*bratSpan
+	links := map[*Chunk]*Chunk{}
+	for i, line := range strings.Split(ann, "\n") {
+		n := i + 1
+		line = strings.TrimSuffix(line, "\r")
+		fail := func(format string, a ...interface{}) error {
+			return &DecodeError{Line: n, Reason: fmt.Sprintf(format, a...)}
+		}
+		fields := strings.Split(line, "\t")
+		switch {
+		case strings.HasPrefix(line, "T"):
+			if len(fields) < 2 {
+				return fail("expected tab-separated text-bound annotation")
+			}
+			sp, err := parseBratSpan(fields[1])
+			if err != nil {
+				return fail("%v", err)
+			}
+			spans[fields[0]] = sp
+			if sp.kind != BratChunkType {
+				entities = append(entities, sp)
+			} else if chunks[chunkSpan{sp.begin, sp.end}] == nil {
+				return fail("%s %d %d does not match a chunk", sp.kind, sp.begin, sp.end)
+			}
+		case strings.HasPrefix(line, "R"):
+			if len(fields) < 2 {
+				return fail("expected tab-separated relation")
+			}
+			args := strings.Fields(fields[1])
+			if len(args) == 0 || args[0] != BratRelationType {
+				continue // not a dependency
+			}
+			if len(args) != 3 {
+				return fail("expected Arg1 and Arg2")
+			}
+			var dep, head *Chunk
+			for _, arg := range args[1:] {
+				name, id, _ := strings.Cut(arg, ":")
+				sp := spans[id]
+				if sp == nil || sp.kind != BratChunkType {
+					return fail("argument %s is not a %s annotation", arg, BratChunkType)
+				}
+				switch name {
+				case "Arg1":
+					dep = chunks[chunkSpan{sp.begin, sp.end}]
+				case "Arg2":
+					head = chunks[chunkSpan{sp.begin, sp.end}]
+				}
+			}
+			if dep == nil || head == nil {
+				return fail("expected Arg1 and Arg2")
+			}
+			if dep.sentence != head.sentence {
+				return fail("relation crosses sentences")
+			}
+			links[dep] = head
+		}
+	}
+	for _, s := range sentences {
+		for _, c := range s.Chunks {
+			c.Link = -1
+			if head, ok := links[c]; ok {
+				c.Link = head.Id
+			}
+			for _, t := range c.Tokens {
+				t.Ne = "O"
+			}
+		}
+	}
+	for _, e := range entities {
+		prefix := "B-"
+		for _, s := range sentences {
+			for _, t := range s.Tokens() {
+				if t.Begin-shift < e.end && t.End-shift > e.begin {
+					t.Ne = prefix + e.kind
+					prefix = "I-"
+				}
+			}
+		}
+	}
+	for _, s := range sentences {
+		s.Resolve()
+	}
+	return nil
+}
+
+// Parses "TYPE START END" or, for discontinuous spans, "TYPE START END;START
+// END...", which are taken to cover everything from the first start to the
+// last end.
+func parseBratSpan(field string) (*bratSpan, error) {
+	kind, offsets, ok := strings.Cut(field, " ")
+	if !ok {
+		return nil, fmt.Errorf("missing offsets in %q", field)
+	}
+	sp := &bratSpan{kind: kind, begin: -1}
+	for _, fragment := range strings.Split(offsets, ";") {
+		pair := strings.Fields(fragment)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("invalid offsets %q", fragment)
+		}
+		begin, err1 := strconv.Atoi(pair[0])
+		end, err2 := strconv.Atoi(pair[1])
+		if err1 != nil || err2 != nil || begin > end {
+			return nil, fmt.Errorf("invalid offsets %q", fragment)
+		}
+		if sp.begin < 0 {
+			sp.begin = begin
+		}
+		sp.end = end
+	}
+	return sp, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var latticeTreeAnn = `T1	Bunsetsu 0 3	太郎は
+T2	Bunsetsu 3 6	花子が
+T3	Bunsetsu 6 11	読んでいる
+T4	Bunsetsu 11 13	本を
+T5	Bunsetsu 13 16	次郎に
+T6	Bunsetsu 16 19	渡した
+T7	PERSON 0 2	太郎
+T8	PERSON 3 5	花子
+T9	PERSON 13 15	次郎
+R1	Dep Arg1:T1 Arg2:T6
+R2	Dep Arg1:T2 Arg2:T3
+R3	Dep Arg1:T3 Arg2:T4
+R4	Dep Arg1:T4 Arg2:T6
+R5	Dep Arg1:T5 Arg2:T6
+`
+
+// Returns latticeTree with its person names tagged.
+func taggedTree() *Sentence {
+	s := NewSentence(latticeTree)
+	for _, c := range []int{0, 1, 4} {
+		s.Chunks[c].Tokens[0].Ne = "B-PERSON"
+	}
+	return s
+}
+
+func neTags(s *Sentence) []string {
+	tags := []string{}
+	for _, t := range s.Tokens() {
+		tags = append(tags, t.Ne)
+	}
+	return tags
+}
+
+func TestToBrat(t *testing.T) {
+	txt, ann := taggedTree().ToBrat()
+	if expected := "太郎は花子が読んでいる本を次郎に渡した"; txt != expected {
+		t.Errorf("expected %q got %q", expected, txt)
+	}
+	if ann != latticeTreeAnn {
+		t.Errorf("Echo: expected\n%s\ngot\n%s", latticeTreeAnn, ann)
+	}
+}
+
+func TestImportBrat(t *testing.T) {
+	s := taggedTree()
+	_, ann := s.ToBrat()
+	// 花子が now depends on 本を, 次郎 is no longer a person, and
+	// 読んでいる本 is an artifact.
+	ann = strings.Replace(ann, "R2\tDep Arg1:T2 Arg2:T3", "R2\tDep Arg1:T2 Arg2:T4", 1)
+	ann = strings.Replace(ann, "T9\tPERSON 13 15\t次郎\n", "T9\tARTIFACT 6 12\t読んでいる本\n", 1)
+	if err := s.ImportBrat(ann); err != nil {
+		t.Fatal(err)
+	}
+	if s.Chunks[1].Link != 3 || s.Chunks[1].Parent() != s.Chunks[3] {
+		t.Errorf("expected chunk 1 to link to 3, got %d", s.Chunks[1].Link)
+	}
+	expected := "[B-PERSON O B-PERSON O B-ARTIFACT I-ARTIFACT I-ARTIFACT I-ARTIFACT O O O O O]"
+	if tags := strings.Join(neTags(s), " "); "["+tags+"]" != expected {
+		t.Errorf("expected %s got [%s]", expected, tags)
+	}
+
+	// Without relations every chunk becomes a root.
+	s = taggedTree()
+	if err := s.ImportBrat(""); err != nil {
+		t.Fatal(err)
+	}
+	if s.Root() != s.Chunks[0] || len(s.Chunks[5].Children()) != 0 {
+		t.Errorf("expected links to be cleared")
+	}
+}
+
+func TestImportBratErrors(t *testing.T) {
+	for ann, line := range map[string]int{
+		"T1\tBunsetsu 0 4\t太郎は花\n":                   1,
+		latticeTreeAnn + "R6\tDep Arg1:T1 Arg2:T7\n": 15,
+		latticeTreeAnn + "R6\tDep Arg1:T1\n":         15,
+		"T1\tPERSON 2 x\t太\n":                        1,
+	} {
+		s := taggedTree()
+		err := s.ImportBrat(ann)
+		var de *DecodeError
+		if !errors.As(err, &de) || de.Line != line {
+			t.Errorf("%q: expected error at line %d, got %v", ann, line, err)
+		}
+		if s.Chunks[0].Link != 5 || s.Chunks[0].Tokens[0].Ne != "B-PERSON" {
+			t.Errorf("%q: sentence changed on error", ann)
+		}
+	}
+}
+
+func TestDocumentBrat(t *testing.T) {
+	s1, s2 := taggedTree(), NewSentence(outputCorrect)
+	for _, tok := range s2.Tokens() {
+		tok.Begin += 20
+		tok.End += 20
+	}
+	d := &Document{Text: "太郎は花子が読んでいる本を次郎に渡した hello，未知語", Sentences: []*Sentence{s1, s2}}
+	txt, ann := d.ToBrat()
+	if txt != d.Text || !strings.Contains(ann, "T7\tBunsetsu 20 29\thello，未知語\n") {
+		t.Errorf("unexpected annotations\n%s", ann)
+	}
+	if err := d.ImportBrat(ann + "T11\tARTIFACT 26 28\t未知\n"); err != nil {
+		t.Fatal(err)
+	}
+	if tag := s2.Chunks[0].Tokens[2].Ne; tag != "B-ARTIFACT" {
+		t.Errorf("expected B-ARTIFACT, got %s", tag)
+	}
+	if err := d.ImportBrat(ann + "R6\tDep Arg1:T7 Arg2:T1\n"); err == nil {
+		t.Errorf("expected an error for a relation across sentences")
+	}
+}
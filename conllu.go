@@ -0,0 +1,562 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ToCoNLLU returns s in CoNLL-U format. Chunk dependencies are converted
+// to word-level heads: within a chunk every token depends on the chunk's
+// head token (Chunk.Head), and the head token depends on the head token of
+// the chunk it links to. UPOS and FEATS are mapped from the UniDic (or
+// IPADIC) part of speech and conjugation, DEPREL is guessed from the parts
+// of speech involved, and the chunk structure is kept in MISC as
+// BunsetuBILabel and BunsetuPositionType so that a CoNLLUDecoder can
+// recover it. The conjugation, which FEATS only partly reflects, is kept
+// in MISC as CType and CForm.
+func (s *Sentence) ToCoNLLU() string {
+	var b strings.Builder
+	for _, comment := range s.Comments {
+		if _, ok := commentID(comment); ok || isTextComment(comment) {
+			continue // replaced by the sent_id and text comments below
+		}
+		b.WriteString(comment)
+		b.WriteByte('\n')
+	}
+	if s.ID != "" {
+		fmt.Fprintf(&b, "# sent_id = %s\n", s.ID)
+	}
+	tokens := s.Tokens()
+	fmt.Fprintf(&b, "# text = %s\n", strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s.Text()))
+
+	ids := make(map[*Token]int, len(tokens))
+	for i, t := range tokens {
+		ids[t] = i + 1
+	}
+	upos := make(map[*Token]string, len(tokens))
+	for _, c := range s.Chunks {
+		for i, t := range c.Tokens {
+			upos[t] = uposOf(t, i > int(c.Head))
+		}
+	}
+
+	for _, c := range s.Chunks {
+		if len(c.Tokens) == 0 {
+			continue
+		}
+		head := chunkHead(c)
+		for i, t := range c.Tokens {
+			h, rel := 0, "root"
+			switch {
+			case t != head:
+				h, rel = ids[head], intraRel(t, upos[t], upos[head], i < int(c.Head))
+			case c.Parent() != nil && len(c.Parent().Tokens) > 0:
+				parent := chunkHead(c.Parent())
+				h, rel = ids[parent], interRel(c, upos[t], upos[parent])
+			}
+
+			misc := []string{"BunsetuBILabel=I", "BunsetuPositionType=" + positionType(c, i)}
+			if i == 0 {
+				misc[0] = "BunsetuBILabel=B"
+			}
+			if t.CForm != "" && t.CForm != "*" {
+				misc = append(misc, "CForm="+t.CForm)
+			}
+			if t.CType != "" && t.CType != "*" {
+				misc = append(misc, "CType="+t.CType)
+			}
+			if t.Ne != "" && t.Ne != "O" {
+				misc = append(misc, "NE="+t.Ne)
+			}
+			if n := ids[t]; n == len(tokens) || tokens[n].Begin <= t.End {
+				misc = append(misc, "SpaceAfter=No")
+			}
+
+			fmt.Fprintf(&b, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t_\t%s\n",
+				ids[t], conlluField(t.Surface()), conlluField(conlluLemma(t)),
+				upos[t], conlluField(xpos(t)), feats(t), h, rel, strings.Join(misc, "|"))
+		}
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Returns the head token of c, or its last token if Head is out of range.
+func chunkHead(c *Chunk) *Token {
+	if t := c.HeadToken(); t != nil {
+		return t
+	}
+	return c.Tokens[len(c.Tokens)-1]
+}
+
+// Returns the BunsetuPositionType of the i-th token of c, as used by the
+// UD Japanese treebanks.
+func positionType(c *Chunk, i int) string {
+	switch {
+	case i == int(c.Head):
+		return "SEM_HEAD"
+	case i == int(c.Tail):
+		return "SYN_HEAD"
+	case i > int(c.Head):
+		return "FUNC"
+	}
+	return "CONT"
+}
+
+func isTextComment(line string) bool {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	rest, ok := strings.CutPrefix(line, "text")
+	return ok && strings.HasPrefix(strings.TrimSpace(rest), "=")
+}
+
+// CoNLL-U fields may not be empty or contain tabs or newlines.
+func conlluField(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ").Replace(s)
+}
+
+func conlluLemma(t *Token) string {
+	for _, lemma := range []string{t.OrthBase, t.Lemma} {
+		if lemma != "" && lemma != "*" {
+			return lemma
+		}
+	}
+	return t.Surface()
+}
+
+// Returns the part of speech joined with hyphens, e.g. "名詞-普通名詞-一般".
+func xpos(t *Token) string {
+	var pos []string
+	for _, p := range []string{t.Pos1, t.Pos2, t.Pos3, t.Pos4} {
+		if p != "" && p != "*" {
+			pos = append(pos, p)
+		}
+	}
+	return strings.Join(pos, "-")
+}
+
+// Returns the UPOS of t. Auxiliary verbs and adjectives (非自立可能)
+// following the head of their chunk are AUX.
+func uposOf(t *Token, afterHead bool) string {
+	switch t.Pos1 {
+	case "名詞":
+		switch t.Pos2 {
+		case "固有名詞":
+			return "PROPN"
+		case "数詞", "数":
+			return "NUM"
+		case "代名詞":
+			return "PRON"
+		case "助動詞語幹":
+			return "AUX"
+		}
+		return "NOUN"
+	case "代名詞":
+		return "PRON"
+	case "形状詞":
+		if t.Pos2 == "助動詞語幹" {
+			return "AUX"
+		}
+		return "ADJ"
+	case "連体詞":
+		return "DET"
+	case "副詞":
+		return "ADV"
+	case "接続詞":
+		return "CCONJ"
+	case "感動詞", "フィラー":
+		return "INTJ"
+	case "動詞", "形容詞":
+		if afterHead && (t.Pos2 == "非自立可能" || t.Pos2 == "非自立") {
+			return "AUX"
+		}
+		if t.Pos1 == "形容詞" {
+			return "ADJ"
+		}
+		return "VERB"
+	case "助動詞":
+		return "AUX"
+	case "助詞":
+		switch t.Pos2 {
+		case "接続助詞", "準体助詞":
+			return "SCONJ"
+		case "終助詞":
+			return "PART"
+		case "並立助詞":
+			return "CCONJ"
+		}
+		return "ADP"
+	case "接頭辞", "接頭詞":
+		return "NOUN"
+	case "接尾辞":
+		switch t.Pos2 {
+		case "形状詞的", "形容詞的":
+			return "ADJ"
+		case "動詞的":
+			return "VERB"
+		case "名詞的":
+			return "NOUN"
+		}
+		return "PART"
+	case "補助記号", "記号":
+		switch t.Pos2 {
+		case "句点", "読点", "括弧開", "括弧閉":
+			return "PUNCT"
+		}
+		return "SYM"
+	case "空白":
+		return "SYM"
+	}
+	return "X"
+}
+
+// Returns the UD FEATS of t, sorted by name, or "_".
+func feats(t *Token) string {
+	var f []string
+	if t.Pos1 == "代名詞" || t.Pos2 == "代名詞" {
+		f = append(f, "PronType=Prs")
+	}
+	if t.Pos2 == "数詞" || t.Pos2 == "数" {
+		f = append(f, "NumType=Card")
+	}
+	if t.Pos2 == "固有名詞" {
+		switch t.Pos3 {
+		case "人名":
+			f = append(f, "NameType=Prs")
+		case "地名", "地域":
+			f = append(f, "NameType=Geo")
+		case "組織":
+			f = append(f, "NameType=Com")
+		}
+	}
+	form, _, _ := strings.Cut(t.CForm, "-")
+	switch form {
+	case "命令形", "命令ｅ", "命令ｒｏ", "命令ｙｏ":
+		f = append(f, "Mood=Imp")
+	case "仮定形":
+		f = append(f, "Mood=Cnd")
+	case "終止形", "基本形":
+		f = append(f, "VerbForm=Fin")
+	}
+	switch t.CType {
+	case "助動詞-ナイ", "助動詞-ヌ", "特殊・ナイ", "特殊・ヌ":
+		f = append(f, "Polarity=Neg")
+	case "助動詞-マス", "助動詞-デス", "特殊・マス", "特殊・デス":
+		f = append(f, "Polite=Form")
+	}
+	if len(f) == 0 {
+		return "_"
+	}
+	sort.Strings(f)
+	return strings.Join(f, "|")
+}
+
+func isNominal(upos string) bool {
+	switch upos {
+	case "NOUN", "PROPN", "PRON", "NUM":
+		return true
+	}
+	return false
+}
+
+// Returns the DEPREL of a token attached to the head of its own chunk.
+func intraRel(t *Token, upos, headUpos string, beforeHead bool) string {
+	switch upos {
+	case "ADP":
+		return "case"
+	case "AUX":
+		if isNominal(headUpos) && (t.Lemma == "だ" || t.Lemma == "です") {
+			return "cop"
+		}
+		return "aux"
+	case "SCONJ", "PART":
+		return "mark"
+	case "PUNCT":
+		return "punct"
+	case "CCONJ":
+		return "cc"
+	case "DET":
+		return "det"
+	}
+	if beforeHead {
+		switch {
+		case isNominal(upos):
+			return "compound"
+		case upos == "ADJ":
+			return "amod"
+		}
+	}
+	return "dep"
+}
+
+// Returns the DEPREL of the head of chunk c attached to the head of its
+// parent chunk. Nominal dependents are told apart by their case particle.
+func interRel(c *Chunk, upos, parentUpos string) string {
+	switch {
+	case upos == "PUNCT":
+		return "punct"
+	case isNominal(upos):
+		if isNominal(parentUpos) {
+			return "nmod"
+		}
+		if f := c.FuncToken(); f != nil && f != c.HeadToken() && f.Pos1 == "助詞" {
+			switch f.Surface() {
+			case "が":
+				return "nsubj"
+			case "を":
+				return "obj"
+			}
+		}
+		return "obl"
+	case upos == "VERB" || upos == "ADJ" || upos == "AUX":
+		if isNominal(parentUpos) {
+			return "acl"
+		}
+		return "advcl"
+	case upos == "ADV":
+		return "advmod"
+	case upos == "DET":
+		return "det"
+	case upos == "CCONJ":
+		return "cc"
+	case upos == "INTJ":
+		return "discourse"
+	}
+	return "dep"
+}
+
+// CoNLLUDecoder reads sentences in CoNLL-U format, such as those written by
+// Sentence.ToCoNLLU. Chunks are rebuilt from BunsetuBILabel (a token
+// without one starts a new chunk), chunk heads from BunsetuPositionType
+// and chunk links from the HEAD column. The conjugation is read from
+// CType and CForm in MISC. FEATS and DEPREL are not read, since ToCoNLLU
+// derives them from the part of speech and conjugation. Chunk scores are
+// not kept by CoNLL-U and are left at 0; multiword token and empty node
+// lines are skipped.
+type CoNLLUDecoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+// Returns a new CoNLLUDecoder reading from r.
+func NewCoNLLUDecoder(r io.Reader) *CoNLLUDecoder {
+	return &CoNLLUDecoder{r: bufio.NewReader(r)}
+}
+
+// A word line of a CoNLL-U sentence.
+type conlluWord struct {
+	token *Token
+	id    int
+	head  int
+	misc  map[string]string
+}
+
+// Next returns the next sentence, or io.EOF when the input is exhausted.
+// When a line is malformed the rest of its sentence is skipped and a
+// *DecodeError is returned; decoding may continue with the next call.
+func (d *CoNLLUDecoder) Next() (*Sentence, error) {
+	var (
+		comments []string
+		words    []*conlluWord
+		first    *DecodeError
+	)
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		d.line++
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if len(comments) == 0 && len(words) == 0 && first == nil {
+				continue
+			}
+			break
+		}
+		if first != nil {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comments = append(comments, line)
+			continue
+		}
+		w, decodeErr := parseCoNLLUWord(line)
+		if decodeErr != nil {
+			decodeErr.Line = d.line
+			first = decodeErr
+			continue
+		}
+		if w == nil {
+			continue
+		}
+		if w.id != len(words)+1 {
+			first = &DecodeError{Line: d.line, Reason: fmt.Sprintf("expected word %d, got %d", len(words)+1, w.id)}
+			continue
+		}
+		words = append(words, w)
+	}
+	if first != nil {
+		return nil, first
+	}
+	if len(comments) == 0 && len(words) == 0 {
+		return nil, io.EOF
+	}
+	return buildCoNLLUSentence(comments, words), nil
+}
+
+// Parses a word line; multiword tokens and empty nodes yield nil.
+func parseCoNLLUWord(line string) (*conlluWord, *DecodeError) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 10 {
+		return nil, &DecodeError{Reason: fmt.Sprintf("expected 10 fields, got %d", len(fields))}
+	}
+	if strings.ContainsAny(fields[0], "-.") {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, &DecodeError{Column: 1, Reason: fmt.Sprintf("invalid ID %q", fields[0])}
+	}
+	head, err := strconv.Atoi(fields[6])
+	if err != nil {
+		column := len(strings.Join(fields[:6], "\t")) + 2
+		return nil, &DecodeError{Column: column, Reason: fmt.Sprintf("invalid HEAD %q", fields[6])}
+	}
+
+	t := &Token{Orth: fields[1], Lemma: "*", OrthBase: "*", Ne: "O", surface: fields[1], schema: CoNLLU}
+	if fields[2] != "_" {
+		t.Lemma, t.OrthBase = fields[2], fields[2]
+	}
+	pos := []*string{&t.Pos1, &t.Pos2, &t.Pos3, &t.Pos4}
+	parts := strings.Split(fields[4], "-")
+	for i, p := range pos {
+		*p = "*"
+		if i < len(parts) && fields[4] != "_" {
+			*p = parts[i]
+		}
+	}
+
+	misc := map[string]string{}
+	if fields[9] != "_" {
+		for _, kv := range strings.Split(fields[9], "|") {
+			k, v, _ := strings.Cut(kv, "=")
+			misc[k] = v
+		}
+	}
+	if ne, ok := misc["NE"]; ok {
+		t.Ne = ne
+	}
+	t.CType, t.CForm = "*", "*"
+	if cType, ok := misc["CType"]; ok {
+		t.CType = cType
+	}
+	if cForm, ok := misc["CForm"]; ok {
+		t.CForm = cForm
+	}
+	return &conlluWord{token: t, id: id, head: head, misc: misc}, nil
+}
+
+func buildCoNLLUSentence(comments []string, words []*conlluWord) *Sentence {
+	// The sent_id and text comments are kept as ID and text, and written
+	// back from there.
+	s := &Sentence{schema: CoNLLU}
+	for _, comment := range comments {
+		if id, ok := commentID(comment); ok && s.ID == "" {
+			s.ID = id
+			continue
+		}
+		if isTextComment(comment) {
+			_, text, _ := strings.Cut(comment, "=")
+			s.text = strings.TrimSpace(text)
+			continue
+		}
+		s.Comments = append(s.Comments, comment)
+	}
+
+	// Group the words into chunks, remembering each word's chunk.
+	var groups [][]*conlluWord
+	chunkOf := make([]int, len(words)+1)
+	for _, w := range words {
+		if label, ok := w.misc["BunsetuBILabel"]; !ok || label != "I" || len(groups) == 0 {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], w)
+		chunkOf[w.id] = len(groups) - 1
+	}
+
+	var offset position
+	for i, g := range groups {
+		c := &Chunk{Id: int64(i), Link: -1, Head: -1, Tail: -1}
+		for j, w := range g {
+			switch w.misc["BunsetuPositionType"] {
+			case "SEM_HEAD":
+				c.Head = int64(j)
+			case "SYN_HEAD":
+				c.Tail = int64(j)
+			}
+			offset = w.token.place(offset, false)
+			if w.misc["SpaceAfter"] != "No" {
+				offset = offset.after(" ")
+			}
+			c.Tokens = append(c.Tokens, w.token)
+		}
+		if c.Head < 0 {
+			// Without position types, the head is the word whose
+			// head lies outside the chunk.
+			c.Head = 0
+			for j, w := range g {
+				if w.head == 0 || w.head > len(words) || chunkOf[w.head] != i {
+					c.Head = int64(j)
+					break
+				}
+			}
+		}
+		if c.Tail < 0 {
+			c.Tail = c.Head
+		}
+		if h := g[c.Head].head; h > 0 && h <= len(words) && chunkOf[h] != i {
+			c.Link = int64(chunkOf[h])
+		}
+		s.Chunks = append(s.Chunks, c)
+	}
+	s.Resolve()
+	return s
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var outputCoNLLU = `# sent_id = t1
+# text = 太郎は花子が読んでいる本を次郎に渡した
+1	太郎	太郎	PROPN	名詞-固有名詞-人名-名	NameType=Prs	12	obl	_	BunsetuBILabel=B|BunsetuPositionType=SEM_HEAD|SpaceAfter=No
+2	は	は	ADP	助詞-係助詞	_	1	case	_	BunsetuBILabel=I|BunsetuPositionType=SYN_HEAD|SpaceAfter=No
+3	花子	花子	PROPN	名詞-固有名詞-人名-名	NameType=Prs	5	nsubj	_	BunsetuBILabel=B|BunsetuPositionType=SEM_HEAD|SpaceAfter=No
+4	が	が	ADP	助詞-格助詞	_	3	case	_	BunsetuBILabel=I|BunsetuPositionType=SYN_HEAD|SpaceAfter=No
+5	読ん	読ん	VERB	動詞-一般	_	8	acl	_	BunsetuBILabel=B|BunsetuPositionType=SEM_HEAD|CForm=連用形-撥音便|CType=五段-マ行|SpaceAfter=No
+6	で	で	SCONJ	助詞-接続助詞	_	5	mark	_	BunsetuBILabel=I|BunsetuPositionType=FUNC|SpaceAfter=No
+7	いる	いる	AUX	動詞-非自立可能	_	5	aux	_	BunsetuBILabel=I|BunsetuPositionType=SYN_HEAD|CForm=連体形-一般|CType=上一段-ア行|SpaceAfter=No
+8	本	本	NOUN	名詞-普通名詞-一般	_	12	obj	_	BunsetuBILabel=B|BunsetuPositionType=SEM_HEAD|SpaceAfter=No
+9	を	を	ADP	助詞-格助詞	_	8	case	_	BunsetuBILabel=I|BunsetuPositionType=SYN_HEAD|SpaceAfter=No
+10	次郎	次郎	PROPN	名詞-固有名詞-人名-名	NameType=Prs	12	obl	_	BunsetuBILabel=B|BunsetuPositionType=SEM_HEAD|SpaceAfter=No
+11	に	に	ADP	助詞-格助詞	_	10	case	_	BunsetuBILabel=I|BunsetuPositionType=SYN_HEAD|SpaceAfter=No
+12	渡し	渡し	VERB	動詞-一般	_	0	root	_	BunsetuBILabel=B|BunsetuPositionType=SEM_HEAD|CForm=連用形-一般|CType=五段-サ行|SpaceAfter=No
+13	た	た	AUX	助動詞	VerbForm=Fin	12	aux	_	BunsetuBILabel=I|BunsetuPositionType=SYN_HEAD|CForm=終止形-一般|CType=助動詞-タ|SpaceAfter=No
+
+`
+
+func TestToCoNLLU(t *testing.T) {
+	s := NewSentence(latticeTree)
+	s.ID = "t1"
+	if output := s.ToCoNLLU(); output != outputCoNLLU {
+		t.Errorf("expected\n%s\ngot\n%s", outputCoNLLU, output)
+	}
+}
+
+func TestCoNLLUDecoder(t *testing.T) {
+	expected := NewSentence(latticeTree)
+	expected.Chunks[0].Tokens[0].Ne = "B-PERSON"
+	input := strings.Repeat(expected.ToCoNLLU(), 2)
+
+	d := NewCoNLLUDecoder(strings.NewReader(input))
+	for n := 0; n < 2; n++ {
+		s, err := d.Next()
+		if err != nil {
+			t.Fatalf("sentence %d: %v", n, err)
+		}
+		if len(s.Chunks) != len(expected.Chunks) {
+			t.Fatalf("sentence %d: expected %d chunks, got %d", n, len(expected.Chunks), len(s.Chunks))
+		}
+		for i, c := range s.Chunks {
+			e := expected.Chunks[i]
+			if c.Id != e.Id || c.Link != e.Link || c.Head != e.Head || c.Tail != e.Tail || len(c.Tokens) != len(e.Tokens) {
+				t.Errorf("chunk %d: expected %d %dD %d/%d, got %d %dD %d/%d",
+					i, e.Id, e.Link, e.Head, e.Tail, c.Id, c.Link, c.Head, c.Tail)
+				continue
+			}
+			for j, tok := range c.Tokens {
+				et := e.Tokens[j]
+				if tok.Surface() != et.Surface() || tok.Pos1 != et.Pos1 || tok.CType != et.CType || tok.CForm != et.CForm ||
+					tok.Ne != et.Ne || tok.Begin != et.Begin || tok.End != et.End {
+					t.Errorf("chunk %d token %d: expected %+v, got %+v", i, j, et, tok)
+				}
+			}
+		}
+		if c := s.Chunks[1].Parent(); c != s.Chunks[2] {
+			t.Errorf("sentence %d: chunks not resolved", n)
+		}
+	}
+	if _, err := d.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestCoNLLURoundTrip(t *testing.T) {
+	s, err := NewCoNLLUDecoder(strings.NewReader(outputCoNLLU)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := s.ToCoNLLU(); output != outputCoNLLU {
+		t.Errorf("expected\n%s\ngot\n%s", outputCoNLLU, output)
+	}
+}
+
+func TestCoNLLUToLattice(t *testing.T) {
+	s, err := NewCoNLLUDecoder(strings.NewReader(outputCoNLLU)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Schema() != CoNLLU || len(s.Comments) != 0 {
+		t.Fatalf("expected schema %s and no comments, got %v and %q", CoNLLU.Name(), s.Schema(), s.Comments)
+	}
+	expected := "# S-ID:t1\n" +
+		"* 0 5D 0/1 0.000000\n" +
+		"太郎\t名詞,固有名詞,人名,名,*,*,太郎\tO\n" +
+		"は\t助詞,係助詞,*,*,*,*,は\tO\n"
+	if output := s.ToLattice(); !strings.HasPrefix(output, expected) {
+		t.Errorf("Echo: expected prefix %q got %q", expected, output)
+	}
+	if output := s.ToLattice(); !strings.Contains(output, "\n渡し\t動詞,一般,*,*,五段-サ行,連用形-一般,渡し\tO\n") {
+		t.Errorf("expected the conjugation in %q", output)
+	}
+}
+
+func TestCoNLLUDecoderWithoutBunsetu(t *testing.T) {
+	input := "# sent_id = plain\n" +
+		"1\t本\t本\tNOUN\t_\t_\t3\tobj\t_\t_\n" +
+		"2\tを\tを\tADP\t_\t_\t1\tcase\t_\t_\n" +
+		"3\t読む\t読む\tVERB\t_\t_\t0\troot\t_\t_\n"
+	s, err := NewCoNLLUDecoder(strings.NewReader(input)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID != "plain" || len(s.Chunks) != 3 {
+		t.Fatalf("expected 3 chunks in sentence plain, got %d in %q", len(s.Chunks), s.ID)
+	}
+	if s.Chunks[0].Link != 2 || s.Chunks[1].Link != 0 || s.Chunks[2].Link != -1 {
+		t.Errorf("unexpected links %d %d %d", s.Chunks[0].Link, s.Chunks[1].Link, s.Chunks[2].Link)
+	}
+	if text := surfaceText(s.Tokens()); text != "本 を 読む" {
+		t.Errorf("expected spaces between tokens, got %q", text)
+	}
+	if s.Chunks[2].Tokens[0].ByteBegin != 8 {
+		t.Errorf("expected 読む at byte 8, got %d", s.Chunks[2].Tokens[0].ByteBegin)
+	}
+	input = "# text = 本を  読む\n" + input
+	if s, _ = NewCoNLLUDecoder(strings.NewReader(input)).Next(); s.Text() != "本を  読む" {
+		t.Errorf("expected the text comment, got %q", s.Text())
+	}
+}
+
+func TestCoNLLUDecoderError(t *testing.T) {
+	input := "1\t本\t本\tNOUN\t_\t_\tx\t_\t_\t_\n\n" +
+		"1\t本\t本\tNOUN\t_\t_\t0\troot\t_\t_\n"
+	d := NewCoNLLUDecoder(strings.NewReader(input))
+	_, err := d.Next()
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 1 || de.Column != 20 {
+		t.Errorf("expected error at line 1, column 20, got %v", err)
+	}
+	if s, err := d.Next(); err != nil || len(s.Chunks) != 1 {
+		t.Errorf("expected decoding to resume, got %v", err)
+	}
+}
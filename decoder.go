@@ -0,0 +1,344 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DecodeError reports malformed CaboCha lattice input.
+type DecodeError struct {
+	Line   int    `json:"line"`   // 1-based line number
+	Column int    `json:"column"` // 1-based byte column, or 0 for the whole line
+	Reason string `json:"reason"`
+}
+
+func (e *DecodeError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("cabocha: line %d: %s", e.Line, e.Reason)
+	}
+	return fmt.Sprintf("cabocha: line %d, column %d: %s", e.Line, e.Column, e.Reason)
+}
+
+// DecodeOptions control how CaboCha output is turned into tokens.
+type DecodeOptions struct {
+	// Schema maps feature columns onto Token fields. If nil, a Decoder or
+	// Parser detects it from the first sentence whose features identify
+	// it and keeps it for the sentences that follow.
+	Schema FeatureSchema
+	// Unknown controls how the fields of unknown words are filled in.
+	Unknown UnknownPolicy
+	// Strict makes malformed input an error, including sentences that
+	// fail Sentence.Validate. Otherwise malformed lines are skipped (or,
+	// for chunk headers, decoded as far as possible) and reported in
+	// Sentence.Warnings.
+	Strict bool
+	// UTF16 sets Token.UTF16 in addition to the rune and byte offsets.
+	UTF16 bool
+}
+
+// Decoder reads sentences in CaboCha lattice format (-f1) from an input
+// stream, one at a time, so arbitrarily large files can be processed in
+// constant memory.
+type Decoder struct {
+	r    *bufio.Reader
+	line int
+	opts DecodeOptions
+}
+
+// Returns a new Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, DecodeOptions{})
+}
+
+// Returns a new Decoder reading from r with the given options.
+func NewDecoderWithOptions(r io.Reader, o DecodeOptions) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), opts: o}
+}
+
+// Next returns the next sentence, or io.EOF when the input is exhausted.
+// A sentence is terminated by an EOS line (or a blank line, or the end of
+// the input). In strict mode, when a line is malformed the rest of its
+// sentence is skipped and a *DecodeError is returned; decoding may continue
+// with the next call.
+func (d *Decoder) Next() (*Sentence, error) {
+	b := &sentenceBuilder{strict: d.opts.Strict, utf16: d.opts.UTF16}
+	var decodeErr error
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" && err == io.EOF {
+			if decodeErr != nil {
+				return nil, decodeErr
+			}
+			if b.empty() {
+				return nil, io.EOF
+			}
+			return d.finish(b)
+		}
+		d.line++
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "EOS" || line == "" {
+			if b.empty() && decodeErr == nil {
+				continue // blank lines between sentences
+			}
+			if decodeErr != nil {
+				return nil, decodeErr
+			}
+			return d.finish(b)
+		}
+		if decodeErr != nil {
+			continue
+		}
+		if err := b.add(line, d.line); err != nil {
+			decodeErr = err
+		}
+	}
+}
+
+func (d *Decoder) finish(b *sentenceBuilder) (*Sentence, error) {
+	s := b.finish(d.opts)
+	if d.opts.Schema == nil {
+		d.opts.Schema = s.detectSchema()
+	}
+	if err := b.validate(s, d.line); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DecodeSentence decodes the first sentence of CaboCha lattice output.
+func DecodeSentence(cabocha_out string, o DecodeOptions) (*Sentence, error) {
+	b := &sentenceBuilder{strict: o.Strict, utf16: o.UTF16}
+	lines := strings.Split(cabocha_out, "\n")
+	n := 0
+	for ; n < len(lines); n++ {
+		line := strings.TrimSuffix(lines[n], "\r")
+		if line == "EOS" || line == "" {
+			break
+		}
+		if err := b.add(line, n+1); err != nil {
+			return nil, err
+		}
+	}
+	s := b.finish(o)
+	if err := b.validate(s, n+1); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Accumulates the lines of one sentence. In strict mode add returns an
+// error for a malformed line; otherwise it records a warning and returns
+// nil.
+type sentenceBuilder struct {
+	s          Sentence
+	c          *Chunk
+	offset     position
+	utf16      bool
+	lines      int
+	strict     bool
+	chunkLines []int // line number of each chunk header
+}
+
+func (b *sentenceBuilder) empty() bool {
+	return b.lines == 0
+}
+
+func (b *sentenceBuilder) add(line string, n int) *DecodeError {
+	if chunkHeaderRe.MatchString(line) {
+		b.chunkLines = append(b.chunkLines, n)
+	}
+	err := b.decode(line)
+	if err == nil {
+		return nil
+	}
+	err.Line = n
+	if b.strict {
+		return err
+	}
+	b.s.Warnings = append(b.s.Warnings, err)
+	return nil
+}
+
+func (b *sentenceBuilder) decode(line string) *DecodeError {
+	b.lines++
+	switch {
+	case isComment(line):
+		b.s.Comments = append(b.s.Comments, line)
+		if id, ok := commentID(line); ok && b.s.ID == "" {
+			b.s.ID = id
+		}
+		return nil
+	case chunkHeaderRe.MatchString(line):
+		b.flush()
+		c, err := parseChunk(line)
+		b.c = c
+		return err
+	}
+
+	fields := strings.Split(line, "\t")
+	if len(fields) != 3 {
+		return &DecodeError{Reason: fmt.Sprintf("expected 3 tab-separated fields in token line, got %d", len(fields))}
+	}
+	features, err := splitFeatures(fields[1], !b.strict)
+	if err != nil {
+		column := len(fields[0]) + 2
+		if pe, ok := err.(*csv.ParseError); ok {
+			column += pe.Column - 1
+			err = pe.Err
+		}
+		return &DecodeError{Column: column, Reason: fmt.Sprintf("malformed feature field: %v", err)}
+	}
+	b.addToken(fields[0], features, fields[2])
+	return nil
+}
+
+// Appends a token to the current chunk.
+func (b *sentenceBuilder) addToken(surface string, features []string, ne string) {
+	if b.c == nil {
+		b.c = new(Chunk)
+	}
+	t := newToken(surface, features, ne)
+	b.c.Tokens = append(b.c.Tokens, t)
+	b.offset = t.place(b.offset, b.utf16)
+}
+
+// Splits a MeCab feature string into columns. With lazy set, stray quotes
+// are kept as part of the column instead of being an error.
+func splitFeatures(field string, lazy bool) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(field))
+	r.LazyQuotes = lazy
+	r.FieldsPerRecord = -1
+	return r.Read()
+}
+
+func (b *sentenceBuilder) flush() {
+	if b.c != nil {
+		b.s.Chunks = append(b.s.Chunks, b.c)
+		b.c = nil
+	}
+}
+
+// In strict mode, reports the first Validate violation of s at the line of
+// the chunk concerned, or at end for the whole sentence.
+func (b *sentenceBuilder) validate(s *Sentence, end int) *DecodeError {
+	if !b.strict {
+		return nil
+	}
+	for _, v := range s.Validate() {
+		line := end
+		if v.Chunk >= 0 && v.Chunk < len(b.chunkLines) {
+			line = b.chunkLines[v.Chunk]
+		}
+		return &DecodeError{Line: line, Reason: v.String()}
+	}
+	return nil
+}
+
+// Returns the sentence, with token fields set as directed by o.
+func (b *sentenceBuilder) finish(o DecodeOptions) *Sentence {
+	b.flush()
+	s := b.s
+	s.applySchema(o)
+	s.Resolve()
+	return &s
+}
+
+// Comment lines start with '#' and, unlike a token line for the surface
+// "#", contain no tab.
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") && !strings.Contains(line, "\t")
+}
+
+// Extracts a sentence ID from "# S-ID:xxx" (KNP) or "# sent_id = xxx"
+// (CoNLL-U) comment lines.
+func commentID(line string) (string, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	if rest, ok := strings.CutPrefix(line, "S-ID:"); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0], true
+		}
+	}
+	if rest, ok := strings.CutPrefix(line, "sent_id"); ok {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(rest), "="); ok {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// Parses a "* id linkD head/func score" chunk header. Every field is
+// decoded even if an earlier one is malformed; the first problem found is
+// returned.
+func parseChunk(line string) (*Chunk, *DecodeError) {
+	c := new(Chunk)
+	fields := strings.Split(line, " ")
+	if len(fields) != 5 {
+		return c, &DecodeError{Reason: fmt.Sprintf("expected 5 fields in chunk header, got %d", len(fields))}
+	}
+	var first *DecodeError
+	fail := func(i int, format string, a ...interface{}) {
+		if first == nil {
+			column := 1
+			for _, f := range fields[:i] {
+				column += len(f) + 1
+			}
+			first = &DecodeError{Column: column, Reason: fmt.Sprintf(format, a...)}
+		}
+	}
+	var err error
+	if c.Id, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		fail(1, "invalid chunk id %q", fields[1])
+	}
+	link, ok := strings.CutSuffix(fields[2], "D")
+	if c.Link, err = strconv.ParseInt(link, 10, 64); !ok || err != nil {
+		fail(2, "invalid chunk link %q", fields[2])
+	}
+	head, tail, ok := strings.Cut(fields[3], "/")
+	if c.Head, err = strconv.ParseInt(head, 10, 64); !ok || err != nil {
+		fail(3, "invalid head/func field %q", fields[3])
+	}
+	if c.Tail, err = strconv.ParseInt(tail, 10, 64); !ok || err != nil {
+		fail(3, "invalid head/func field %q", fields[3])
+	}
+	if c.Prob, err = strconv.ParseFloat(fields[4], 64); err != nil {
+		fail(4, "invalid score %q", fields[4])
+	}
+	return c, first
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	in := "# S-ID:doc1-1\n" + outputCorrect + "\n" +
+		strings.ReplaceAll(outputCorrect, "\n", "\r\n") +
+		"* 0 -1D 0/0 0.000000\nbroken line\nEOS\n" +
+		"#\t補助記号,一般,*,*,*,*\tO\nEOS"
+	d := NewDecoder(strings.NewReader(in))
+
+	s, err := d.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID != "doc1-1" {
+		t.Errorf("ID: expected %q got %q", "doc1-1", s.ID)
+	}
+	expected := NewSentence(outputCorrect)
+	if output, expected := string(s.ToJSON()), string(expected.ToJSON()); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+
+	s, err = d.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Tokens()) != 4 || s.Tokens()[3].Orth != "語" {
+		t.Errorf("CRLF sentence decoded incorrectly: %s", s.ToJSON())
+	}
+
+	s, err = d.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Warnings) != 1 || s.Warnings[0].Line != 16 {
+		t.Errorf("expected a warning on line 16 got %v", s.Warnings)
+	}
+
+	s, err = d.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Tokens()) != 1 || s.Tokens()[0].Surface() != "#" {
+		t.Errorf("token \"#\" decoded incorrectly: %s", s.ToJSON())
+	}
+
+	if _, err := d.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+}
+
+func TestDecoderStrict(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected DecodeError
+		warnings int // in lenient mode, which accepts stray quotes
+	}{
+		{"* 0 -1D 0/0 0.000000\nbroken line\nEOS\n", DecodeError{Line: 2, Column: 0}, 1},
+		{"* 0 -1X 0/0 0.000000\nEOS\n", DecodeError{Line: 1, Column: 5}, 1},
+		{"* 0 -1D 0/0 0.000000\nhello\t名詞,\"普通\"名詞\tO\nEOS\n", DecodeError{Line: 2, Column: 21}, 0},
+	}
+	for _, test := range tests {
+		d := NewDecoderWithOptions(strings.NewReader(test.in+outputCorrect), DecodeOptions{Strict: true})
+		_, err := d.Next()
+		de, ok := err.(*DecodeError)
+		if !ok || de.Line != test.expected.Line || de.Column != test.expected.Column {
+			t.Errorf("%q: expected error at %d:%d got %v", test.in, test.expected.Line, test.expected.Column, err)
+		}
+		// Decoding resumes with the next sentence.
+		if s, err := d.Next(); err != nil || len(s.Tokens()) != 4 {
+			t.Errorf("%q: expected to resume after the error, got %v", test.in, err)
+		}
+
+		s, err := DecodeSentence(test.in, DecodeOptions{})
+		if err != nil || len(s.Warnings) != test.warnings {
+			t.Errorf("%q: expected %d warnings in lenient mode got %v (%v)", test.in, test.warnings, s.Warnings, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NewlineMode controls how a Splitter treats line breaks.
+type NewlineMode int
+
+const (
+	NewlineBoundary  NewlineMode = iota // every line break ends a sentence
+	NewlineParagraph                    // only blank lines end a sentence
+	NewlineIgnore                       // line breaks are ordinary whitespace
+)
+
+// Splitter splits Japanese text into sentences. Terminators inside
+// brackets (as in 「はい。」と言った。) do not end a sentence. An opening
+// bracket whose closer does not follow before the next line break (as
+// set by Newlines) is ignored.
+type Splitter struct {
+	Terminators string // characters that end a sentence
+	Open        string // opening brackets
+	Close       string // closing brackets, paired by position with Open
+	Newlines    NewlineMode
+}
+
+// DefaultSplitter is used by ParseDocument.
+var DefaultSplitter = &Splitter{
+	Terminators: "。！？!?",
+	Open:        "「『（(【〈《［[",
+	Close:       "」』）)】〉》］]",
+	Newlines:    NewlineBoundary,
+}
+
+// Span is a range [Begin, End) of a string: of bytes for Splitter.Split,
+// and of UTF-16 code units for Token.UTF16.
+type Span struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+// Split returns the spans of the sentences in text. Whitespace between
+// sentences is not part of any span, and empty sentences are dropped.
+func (sp *Splitter) Split(text string) []Span {
+	var spans []Span
+	add := func(begin, end int) {
+		for begin < end {
+			r, n := utf8.DecodeRuneInString(text[begin:end])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			begin += n
+		}
+		for end > begin {
+			r, n := utf8.DecodeLastRuneInString(text[begin:end])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			end -= n
+		}
+		if begin < end {
+			spans = append(spans, Span{begin, end})
+		}
+	}
+
+	begin := 0
+	var open []int // indexes into Open of the brackets not yet closed
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == '\n' && sp.Newlines != NewlineIgnore:
+			if sp.Newlines == NewlineBoundary || sp.blankLineFollows(text, i+n) {
+				add(begin, i)
+				begin = i + n
+				open = open[:0]
+			}
+		case runeIndex(sp.Open, r) != -1:
+			if k := runeIndex(sp.Open, r); sp.closedBeforeBoundary(text, i+n, k) {
+				open = append(open, k)
+			}
+		case runeIndex(sp.Close, r) != -1:
+			// A closer ends its own opener and any left unclosed inside
+			// it; one without a matching opener is ignored.
+			k := runeIndex(sp.Close, r)
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == k {
+					open = open[:j]
+					break
+				}
+			}
+		case len(open) == 0 && strings.ContainsRune(sp.Terminators, r):
+			// Keep runs like "！？" and trailing closing brackets with
+			// the sentence they end.
+			end := i + n
+			for end < len(text) {
+				r, n := utf8.DecodeRuneInString(text[end:])
+				if !strings.ContainsRune(sp.Terminators, r) && !strings.ContainsRune(sp.Close, r) {
+					break
+				}
+				end += n
+			}
+			add(begin, end)
+			begin = end
+			i = end
+			continue
+		}
+		i += n
+	}
+	add(begin, len(text))
+	return spans
+}
+
+// Returns the position of r among the runes of set, or -1.
+func runeIndex(set string, r rune) int {
+	i := 0
+	for _, c := range set {
+		if c == r {
+			return i
+		}
+		i++
+	}
+	return -1
+}
+
+// Reports whether the closer of the k-th opening bracket occurs in text
+// from i on, before the next boundary that Newlines makes a line break.
+// Stray openers, common in web and OCR text, would otherwise stop
+// sentences from being split up to that boundary.
+func (sp *Splitter) closedBeforeBoundary(text string, i, k int) bool {
+	for j, r := range text[i:] {
+		if r == '\n' && (sp.Newlines == NewlineBoundary ||
+			sp.Newlines == NewlineParagraph && sp.blankLineFollows(text, i+j+1)) {
+			return false
+		}
+		if runeIndex(sp.Close, r) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether the line starting at i is blank (or the text ends).
+func (sp *Splitter) blankLineFollows(text string, i int) bool {
+	for ; i < len(text); i++ {
+		switch text[i] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Document is a text of any number of sentences. Token offsets in its
+// sentences are relative to the whole Text.
+type Document struct {
+	Text      string      `json:"text"`
+	Sentences []*Sentence `json:"sentences"`
+}
+
+// ParseDocument splits text into sentences with sp (DefaultSplitter if
+// nil) and parses each one.
+func (p *Parser) ParseDocument(ctx context.Context, text string, sp *Splitter) (*Document, error) {
+	if sp == nil {
+		sp = DefaultSplitter
+	}
+	d := &Document{Text: text}
+	var offset position
+	last := 0
+	for _, span := range sp.Split(text) {
+		offset = offset.after(text[last:span.Begin])
+		last = span.Begin
+		s, err := p.parseAt(ctx, text[span.Begin:span.End], offset)
+		if err != nil {
+			return nil, err
+		}
+		d.Sentences = append(d.Sentences, s)
+	}
+	return d, nil
+}
+
+// ParseDocument parses text with a Parser borrowed from the pool.
+func (pool *ParserPool) ParseDocument(ctx context.Context, text string, sp *Splitter) (*Document, error) {
+	p, err := pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Put(p)
+	return p.ParseDocument(ctx, text, sp)
+}
+
+// ParseDocument parses text with the package-level default parser, split
+// into sentences by DefaultSplitter.
+func ParseDocument(ctx context.Context, text string) (*Document, error) {
+	p, err := DefaultParser()
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseDocument(ctx, text, nil)
+}
+
+// Sets token offsets by locating each token's surface string in text,
+// which starts at base, and keeps text as the sentence's input. MeCab drops
+// whitespace, so whitespace between tokens is skipped; a surface that
+// cannot be found (e.g. after normalization) is assumed to follow the
+// previous token directly.
+func (s *Sentence) align(text string, base position, withUTF16 bool) {
+	s.text = text
+	pos, offset := 0, base
+	for _, t := range s.Tokens() {
+		surface := t.Surface()
+		if i := strings.Index(text[pos:], surface); i >= 0 && isSpace(text[pos:pos+i]) {
+			offset = offset.after(text[pos : pos+i])
+			pos += i + len(surface)
+		}
+		offset = t.place(offset, withUTF16)
+	}
+}
+
+func isSpace(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
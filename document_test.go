@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSplitterSplit(t *testing.T) {
+	paragraph := &Splitter{
+		Terminators: DefaultSplitter.Terminators,
+		Open:        DefaultSplitter.Open,
+		Close:       DefaultSplitter.Close,
+		Newlines:    NewlineParagraph,
+	}
+	ignore := &Splitter{
+		Terminators: DefaultSplitter.Terminators,
+		Open:        DefaultSplitter.Open,
+		Close:       DefaultSplitter.Close,
+		Newlines:    NewlineIgnore,
+	}
+	tests := []struct {
+		sp       *Splitter
+		text     string
+		expected []string
+	}{
+		{DefaultSplitter, "雨だ。風も強い！", []string{"雨だ。", "風も強い！"}},
+		{DefaultSplitter, "本当？！ はい。", []string{"本当？！", "はい。"}},
+		{DefaultSplitter, "「はい。」と言った。次へ。", []string{"「はい。」と言った。", "次へ。"}},
+		{DefaultSplitter, "（笑。）続く", []string{"（笑。）続く"}},
+		{DefaultSplitter, "「はい)。」と言った。次へ。", []string{"「はい)。」と言った。", "次へ。"}},
+		{DefaultSplitter, "はい」。次へ。", []string{"はい」。", "次へ。"}},
+		{DefaultSplitter, "「（笑」。次へ。", []string{"「（笑」。", "次へ。"}},
+		{DefaultSplitter, "（注：これは重要。次の文。\n別の行。", []string{"（注：これは重要。", "次の文。", "別の行。"}},
+		{ignore, "（注：これは重要。次の文。\n別の行。", []string{"（注：これは重要。", "次の文。", "別の行。"}},
+		{paragraph, "「はい。\nそう。」\n\n（注。次。", []string{"「はい。\nそう。」", "（注。", "次。"}},
+		{DefaultSplitter, "見出し\n本文です。", []string{"見出し", "本文です。"}},
+		{paragraph, "一行目\n二行目\n\n次の段落", []string{"一行目\n二行目", "次の段落"}},
+		{DefaultSplitter, " \n\n", nil},
+	}
+	for _, test := range tests {
+		var output []string
+		for _, span := range test.sp.Split(test.text) {
+			output = append(output, test.text[span.Begin:span.End])
+		}
+		if !reflect.DeepEqual(output, test.expected) {
+			t.Errorf("Split(%q): expected %q got %q", test.text, test.expected, output)
+		}
+	}
+}
+
+func TestParseDocumentOffsets(t *testing.T) {
+	text := "hello，未知語。\n未知語"
+	d, err := ParseDocument(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Sentences) != 2 {
+		t.Fatalf("expected 2 sentences got %d", len(d.Sentences))
+	}
+	runes := []rune(text)
+	for _, s := range d.Sentences {
+		for _, tok := range s.Tokens() {
+			if output := string(runes[tok.Begin:tok.End]); output != tok.Surface() {
+				t.Errorf("Echo: expected %q got %q", tok.Surface(), output)
+			}
+			if output := text[tok.ByteBegin:tok.ByteEnd]; output != tok.Surface() {
+				t.Errorf("Echo: expected %q got %q", tok.Surface(), output)
+			}
+		}
+	}
+	if output := d.Sentences[1].Text(); output != "未知語" {
+		t.Errorf("Text: expected %q got %q", "未知語", output)
+	}
+}
+
+func TestSentenceAlign(t *testing.T) {
+	s := NewSentence(outputCorrect)
+	s.align("hello ， 未知語", position{runes: 10, bytes: 20, utf16: 10}, true)
+	expected := [][6]int{
+		{10, 15, 20, 25, 10, 15},
+		{16, 17, 26, 29, 16, 17},
+		{18, 20, 30, 36, 18, 20},
+		{20, 21, 36, 39, 20, 21},
+	}
+	for i, tok := range s.Tokens() {
+		if output := [6]int{tok.Begin, tok.End, tok.ByteBegin, tok.ByteEnd, tok.UTF16.Begin, tok.UTF16.End}; output != expected[i] {
+			t.Errorf("token %d: expected %v got %v", i, expected[i], output)
+		}
+	}
+	if output := s.Text(); output != "hello ， 未知語" {
+		t.Errorf("Text: expected %q got %q", "hello ， 未知語", output)
+	}
+}
+
+func TestTokenOffsets(t *testing.T) {
+	// 𠮷 is outside the Basic Multilingual Plane: one rune, four bytes
+	// and two UTF-16 code units.
+	lattice := "* 0 -1D 0/1 0.000000\n" +
+		"𠮷野家\t名詞,固有名詞,一般,*,*,*\tO\n" +
+		"で\t助詞,格助詞,*,*,*,*\tO\n" +
+		"EOS\n"
+	s, err := DecodeSentence(lattice, DecodeOptions{UTF16: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][6]int{
+		{0, 3, 0, 10, 0, 4},
+		{3, 4, 10, 13, 4, 5},
+	}
+	for i, tok := range s.Tokens() {
+		if output := [6]int{tok.Begin, tok.End, tok.ByteBegin, tok.ByteEnd, tok.UTF16.Begin, tok.UTF16.End}; output != expected[i] {
+			t.Errorf("token %d: expected %v got %v", i, expected[i], output)
+		}
+	}
+	if output := s.Text(); output != "𠮷野家で" {
+		t.Errorf("Text: expected %q got %q", "𠮷野家で", output)
+	}
+
+	if NewSentence(lattice).Tokens()[0].UTF16 != nil {
+		t.Errorf("expected no UTF-16 offsets unless requested")
+	}
+}
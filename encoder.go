@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encoder writes sentences in CaboCha lattice format (-f1). Sentences
+// decoded from CaboCha output and left unmodified are written back
+// byte-for-byte.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// Returns a new Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes s followed by an EOS line. An ID that no comment carries,
+// as for sentences read from CoNLL-U, is written as a "# S-ID:" comment.
+func (e *Encoder) Encode(s *Sentence) error {
+	hasID := false
+	for _, comment := range s.Comments {
+		if _, ok := commentID(comment); ok {
+			hasID = true
+		}
+	}
+	if s.ID != "" && !hasID {
+		fmt.Fprintf(e.w, "# S-ID:%s\n", s.ID)
+	}
+	for _, comment := range s.Comments {
+		e.w.WriteString(comment)
+		e.w.WriteByte('\n')
+	}
+	for _, c := range s.Chunks {
+		fmt.Fprintf(e.w, "* %d %dD %d/%d %f\n", c.Id, c.Link, c.Head, c.Tail, c.Prob)
+		for _, t := range c.Tokens {
+			e.w.WriteString(t.Surface())
+			e.w.WriteByte('\t')
+			e.w.WriteString(joinFeatures(t.featureList()))
+			e.w.WriteByte('\t')
+			e.w.WriteString(t.Ne)
+			e.w.WriteByte('\n')
+		}
+	}
+	e.w.WriteString("EOS\n")
+	return e.w.Flush()
+}
+
+// ToLattice returns s in CaboCha lattice format.
+func (s *Sentence) ToLattice() string {
+	var b strings.Builder
+	NewEncoder(&b).Encode(s)
+	return b.String()
+}
+
+// Joins features into a CSV record, quoting fields the way MeCab
+// dictionaries do: only when they contain a comma or a double quote.
+func joinFeatures(features []string) string {
+	var b strings.Builder
+	for i, f := range features {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if strings.ContainsAny(f, `,"`) {
+			b.WriteByte('"')
+			b.WriteString(strings.ReplaceAll(f, `"`, `""`))
+			b.WriteByte('"')
+		} else {
+			b.WriteString(f)
+		}
+	}
+	return b.String()
+}
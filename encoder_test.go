@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+var latticeQuoted = `* 0 1D 0/1 0.000000
+レスポンス	名詞,普通名詞,一般,*,*,*,レスポンス,レスポンス,レスポンス,レスポンス,レスポンス,外,レスポンス,レスポンス,レスポンス,レスポンス,*,*,*,*,*,*,"1,3",C1,*	O
+を	助詞,格助詞,*,*,*,*,ヲ,を,を,オ,ヲ,和,を,オ,ヲ,ヲ,*,*,*,*,*,*,*,"動詞%F2@0,名詞%F1,形容詞%F2@-1",*	O
+* 1 -1D 0/0 0.000000
+返す	動詞,一般,*,*,五段-サ行,終止形-一般,カエス,返す,返す,カエス,カエス,和,返す,カエス,カエス,カエス,*,*,*,*,*,*,1,C1,*	O
+EOS
+`
+
+var latticeEscapedQuote = `* 0 -1D 0/0 1.316291
+"	補助記号,括弧開,*,*,*,*,,"""","""",,,記号,"""",,,,*,*,*,*,*,*,*,*,*	O
+EOS
+`
+
+func TestToLatticeRoundTrip(t *testing.T) {
+	for _, lattice := range []string{outputCorrect, latticeQuoted, latticeEscapedQuote} {
+		if output := NewSentence(lattice).ToLattice(); output != lattice {
+			t.Errorf("Echo: expected %q got %q", lattice, output)
+		}
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	in := "# S-ID:1\n" + outputCorrect + latticeQuoted + "# S-ID:3 comment\n" + latticeEscapedQuote
+	d := NewDecoder(strings.NewReader(in))
+	var out bytes.Buffer
+	e := NewEncoder(&out)
+	for {
+		s, err := d.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Encode(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if output := out.String(); output != in {
+		t.Errorf("Echo: expected %q got %q", in, output)
+	}
+}
+
+func TestToLatticeFromNamedFields(t *testing.T) {
+	for _, lattice := range []string{outputCorrect, latticeQuoted} {
+		s := NewSentence(lattice)
+		for _, tok := range s.Tokens() {
+			tok.Features = nil
+		}
+		if output := s.ToLattice(); output != lattice {
+			t.Errorf("Echo: expected %q got %q", lattice, output)
+		}
+	}
+}
+
+func TestToLatticeEditedField(t *testing.T) {
+	expected := strings.Replace(latticeQuoted, "返す,返す,カエス", "帰す,返す,カエス", 1)
+
+	s := NewSentence(latticeQuoted)
+	s.Tokens()[2].Lemma = "帰す"
+	if output := s.ToLattice(); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+	if output, _ := s.Tokens()[2].Feature("lemma"); output != "帰す" {
+		t.Errorf("Feature: expected %q got %q", "帰す", output)
+	}
+
+	var loaded Sentence
+	if err := json.Unmarshal(NewSentence(latticeQuoted).ToJSONDocument(""), &loaded); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Tokens()[2].Lemma = "帰す"
+	if output := loaded.ToLattice(); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+}
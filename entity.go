@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"strings"
+)
+
+// Entity is a named entity: a run of tokens merged from their IOB tags.
+type Entity struct {
+	Type      string   `json:"type"`      // e.g. "PERSON"
+	Begin     int      `json:"begin"`     // rune offset of the first token
+	End       int      `json:"end"`       // rune offset just past the last token
+	ByteBegin int      `json:"byteBegin"` // byte offset of the first token
+	ByteEnd   int      `json:"byteEnd"`   // byte offset just past the last token
+	Text      string   `json:"text"`
+	Tokens    []*Token `json:"-"`
+}
+
+// Entities returns the named entities of s, merged from the IOB tags of
+// Token.Ne. An I- tag that does not continue an entity of its type, as
+// when its B- tag is missing, starts a new entity. Begin and End are the
+// offsets of the tokens, which for sentences of a Document are offsets
+// into Document.Text.
+func (s *Sentence) Entities() []Entity {
+	return s.EntitiesWithMode(NENoConstraint)
+}
+
+// EntitiesWithMode returns the named entities of s as Entities does, for
+// output of CaboCha run with the given -n mode. With NEChunkConstraint
+// entities end at chunk boundaries, so an I- tag starting a chunk begins a
+// new entity; otherwise entities may span chunks.
+func (s *Sentence) EntitiesWithMode(mode NEMode) []Entity {
+	var entities []Entity
+	inside := false // the previous token belongs to an entity
+	for _, c := range s.Chunks {
+		for i, t := range c.Tokens {
+			prefix, kind, ok := strings.Cut(t.Ne, "-")
+			if !ok || prefix != "B" && prefix != "I" {
+				inside = false
+				continue
+			}
+			n := len(entities) - 1
+			continues := prefix == "I" && inside && entities[n].Type == kind &&
+				!(mode == NEChunkConstraint && i == 0)
+			if continues {
+				entities[n].Tokens = append(entities[n].Tokens, t)
+			} else {
+				entities = append(entities, Entity{Type: kind, Tokens: []*Token{t}})
+			}
+			inside = true
+		}
+	}
+	for i := range entities {
+		e := &entities[i]
+		first, last := e.Tokens[0], e.Tokens[len(e.Tokens)-1]
+		e.Begin, e.End = first.Begin, last.End
+		e.ByteBegin, e.ByteEnd = first.ByteBegin, last.ByteEnd
+		e.Text = surfaceText(e.Tokens)
+	}
+	return entities
+}
+
+// Entities returns the named entities of every sentence of d in order.
+func (d *Document) Entities() []Entity {
+	var entities []Entity
+	for _, s := range d.Sentences {
+		entities = append(entities, s.Entities()...)
+	}
+	return entities
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"testing"
+)
+
+func entityStrings(entities []Entity) []string {
+	out := []string{}
+	for _, e := range entities {
+		out = append(out, fmt.Sprintf("%s %d %d %s %d", e.Type, e.Begin, e.End, e.Text, len(e.Tokens)))
+	}
+	return out
+}
+
+func TestEntities(t *testing.T) {
+	s := NewSentence(latticeTree)
+	// 太郎 は | 花子 が | 読ん で いる | 本 を | 次郎 に | 渡し た
+	for i, tag := range []string{
+		"B-PERSON", "O",
+		"I-PERSON", "O", // I- without B-
+		"B-ARTIFACT", "I-ARTIFACT", "I-ARTIFACT",
+		"I-ARTIFACT", "O", // continues across a chunk boundary
+		"B-PERSON", "I-LOCATION", // type change
+		"", "O",
+	} {
+		s.Tokens()[i].Ne = tag
+	}
+
+	for mode, expected := range map[NEMode][]string{
+		NENoConstraint: {
+			"PERSON 0 2 太郎 1", "PERSON 3 5 花子 1", "ARTIFACT 6 12 読んでいる本 4",
+			"PERSON 13 15 次郎 1", "LOCATION 15 16 に 1",
+		},
+		NEChunkConstraint: {
+			"PERSON 0 2 太郎 1", "PERSON 3 5 花子 1", "ARTIFACT 6 11 読んでいる 3", "ARTIFACT 11 12 本 1",
+			"PERSON 13 15 次郎 1", "LOCATION 15 16 に 1",
+		},
+	} {
+		if output := entityStrings(s.EntitiesWithMode(mode)); fmt.Sprint(output) != fmt.Sprint(expected) {
+			t.Errorf("mode %d: expected %v got %v", mode, expected, output)
+		}
+	}
+	if output, expected := entityStrings(s.Entities()), entityStrings(s.EntitiesWithMode(NENoConstraint)); fmt.Sprint(output) != fmt.Sprint(expected) {
+		t.Errorf("Entities: expected %v got %v", expected, output)
+	}
+	if e := s.Entities()[2]; e.Tokens[3] != s.Chunks[3].Tokens[0] {
+		t.Errorf("expected the entity's tokens to be the sentence's")
+	} else if e.ByteBegin != 18 || e.ByteEnd != 36 {
+		t.Errorf("expected bytes 18-36, got %d-%d", e.ByteBegin, e.ByteEnd)
+	}
+}
+
+func TestDocumentEntities(t *testing.T) {
+	s1, s2 := NewSentence(latticeTree), NewSentence(latticeTree)
+	s1.Tokens()[0].Ne = "B-PERSON"
+	for _, tok := range s2.Tokens() {
+		tok.Begin += 20
+		tok.End += 20
+	}
+	s2.Tokens()[9].Ne = "B-PERSON"
+	d := &Document{Sentences: []*Sentence{s1, s2}}
+	expected := []string{"PERSON 0 2 太郎 1", "PERSON 33 35 次郎 1"}
+	if output := entityStrings(d.Entities()); fmt.Sprint(output) != fmt.Sprint(expected) {
+		t.Errorf("expected %v got %v", expected, output)
+	}
+	if len(NewSentence(latticeTree).Entities()) != 0 {
+		t.Errorf("expected no entities")
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors returned by Parser. Errors reported by CaboCha itself are wrapped
+// in an *Error whose Err field is one of these values, so they can be
+// tested with errors.Is.
+var (
+	ErrInvalidOption = errors.New("cabocha: invalid option")
+	ErrModelLoad     = errors.New("cabocha: cannot load model")
+	ErrParse         = errors.New("cabocha: parse failed")
+	ErrInvalidInput  = errors.New("cabocha: invalid input")
+	ErrClosed        = errors.New("cabocha: parser is closed")
+
+	ErrDefaultInitialized = errors.New("cabocha: default parser already initialized")
+)
+
+// Error carries the message returned by cabocha_strerror.
+type Error struct {
+	Op  string // "new" or "parse"
+	Msg string // cabocha_strerror output
+	Err error  // one of the Err* values above
+}
+
+func (e *Error) Error() string {
+	if e.Msg == "" {
+		return e.Err.Error()
+	}
+	return "cabocha: " + e.Op + ": " + e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classifies a cabocha_new2 failure message. CaboCha's option parser
+// reports unknown options and missing arguments; anything else at this
+// stage is a failure to open a model, dictionary or rc file.
+func newError(msg string) *Error {
+	err := ErrModelLoad
+	lower := strings.ToLower(msg)
+	if strings.Contains(lower, "unrecognized option") ||
+		strings.Contains(lower, "requires an argument") {
+		err = ErrInvalidOption
+	}
+	return &Error{Op: "new", Msg: msg, Err: err}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewErrorClassification(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want error
+	}{
+		{"unrecognized option `--foo`", ErrInvalidOption},
+		{"`-m' requires an argument", ErrInvalidOption},
+		{"no such file or directory: /nonexistent/dep.ipa", ErrModelLoad},
+		{"invalid model file: /models/dep.unidic", ErrModelLoad},
+		{"unknown format version", ErrModelLoad},
+	}
+	for _, test := range tests {
+		err := newError(test.msg)
+		if !errors.Is(err, test.want) {
+			t.Errorf("newError(%q): expected %v got %v", test.msg, test.want, err.Err)
+		}
+	}
+}
+
+func TestNewParserBadModel(t *testing.T) {
+	p, err := NewParser("--parser-model=/nonexistent/dep.unidic")
+	if err == nil {
+		p.Close()
+		t.Fatal("expected an error for a missing model")
+	}
+	if !errors.Is(err, ErrModelLoad) {
+		t.Errorf("expected %v got %v", ErrModelLoad, err)
+	}
+}
+
+func TestClosedParser(t *testing.T) {
+	p := new(Parser)
+	if _, err := p.ParseToFormat("hello", FormatLattice); err != ErrClosed {
+		t.Errorf("expected %v got %v", ErrClosed, err)
+	}
+}
@@ -33,12 +33,26 @@ package main
 import (
 	c "../"
 	"code.google.com/p/go.net/websocket"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"runtime"
 )
 
+// CaboCha instances are not thread-safe, so every handler borrows one from
+// a pool sized to the number of CPUs.
+var pool *c.ParserPool
+
+func init() {
+	var err error
+	pool, err = c.NewParserPool(runtime.NumCPU(), "")
+	if err != nil {
+		log.Fatal("Could not initialize CaboCha: ", err)
+	}
+}
+
 func bodyReadHelper(w http.ResponseWriter, r *http.Request) string {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -57,7 +71,11 @@ func websocketHandler(ws *websocket.Conn) {
 			break
 		}
 
-		reply := c.ParseToLatticeString(input)
+		reply, err := pool.ParseToFormat(context.Background(), input, c.FormatLattice)
+		if err != nil {
+			log.Println("Parse error:", err)
+			continue
+		}
 		err = websocket.Message.Send(ws, reply)
 		if err != nil {
 			log.Println("WebSocket message not sent:", err)
@@ -75,8 +93,12 @@ func websocketHandlerJSON(ws *websocket.Conn) {
 			break
 		}
 
-		reply := c.ParseToSentence(input).ToJSON()
-		err = websocket.Message.Send(ws, reply)
+		sentence, err := pool.Parse(context.Background(), input)
+		if err != nil {
+			log.Println("Parse error:", err)
+			continue
+		}
+		err = websocket.Message.Send(ws, sentence.ToJSON())
 		if err != nil {
 			log.Println("WebSocket message not sent:", err)
 			break
@@ -86,13 +108,43 @@ func websocketHandlerJSON(ws *websocket.Conn) {
 
 func main() {
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, "%s", c.ParseToLatticeString(bodyReadHelper(w, r)))
+		out, err := pool.ParseToFormat(r.Context(), bodyReadHelper(w, r), c.FormatLattice)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "%s", out)
 	})
 	http.HandleFunc("/xml", func(w http.ResponseWriter, r *http.Request) {
-		w.Write(c.ParseToSentence(bodyReadHelper(w, r)).ToXML())
+		sentence, err := pool.Parse(r.Context(), bodyReadHelper(w, r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(sentence.ToXML())
 	})
 	http.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
-		w.Write(c.ParseToSentence(bodyReadHelper(w, r)).ToJSON())
+		sentence, err := pool.Parse(r.Context(), bodyReadHelper(w, r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(sentence.ToJSON())
+	})
+	// Serves a dependency diagram that can be viewed in a browser, e.g.
+	// http://localhost:8080/svg?q=太郎は花子が読んでいる本を次郎に渡した
+	http.HandleFunc("/svg", func(w http.ResponseWriter, r *http.Request) {
+		input := r.URL.Query().Get("q")
+		if input == "" {
+			input = bodyReadHelper(w, r)
+		}
+		sentence, err := pool.Parse(r.Context(), input)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+		fmt.Fprint(w, sentence.ToSVG(c.SVGOptions{}))
 	})
 	http.Handle("/ws", websocket.Handler(websocketHandler))
 	http.Handle("/ws/json", websocket.Handler(websocketHandlerJSON))
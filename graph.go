@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+// Resolve links every chunk of s to its parent and children according to
+// Link. Sentences are resolved when decoded or parsed; call Resolve again
+// after editing Link or Chunks by hand.
+func (s *Sentence) Resolve() {
+	byId := make(map[int64]*Chunk, len(s.Chunks))
+	for _, c := range s.Chunks {
+		c.sentence = s
+		c.parent = nil
+		c.children = nil
+		byId[c.Id] = c
+	}
+	for _, c := range s.Chunks {
+		if parent, ok := byId[c.Link]; ok && c.Link != -1 && parent != c {
+			c.parent = parent
+			parent.children = append(parent.children, c)
+		}
+	}
+}
+
+// Root returns the chunk that depends on no other chunk (Link == -1), or
+// nil if there is none.
+func (s *Sentence) Root() *Chunk {
+	for _, c := range s.Chunks {
+		if c.Link == -1 {
+			return c
+		}
+	}
+	return nil
+}
+
+// PathBetween returns the chunks on the dependency path from a up to
+// their closest common ancestor and down to b, both ends included. It
+// returns nil if a and b are not connected.
+func (s *Sentence) PathBetween(a, b *Chunk) []*Chunk {
+	up := append([]*Chunk{a}, a.Ancestors()...)
+	index := make(map[*Chunk]int, len(up))
+	for i, c := range up {
+		index[c] = i
+	}
+	var down []*Chunk
+	for c, seen := b, make(map[*Chunk]bool); c != nil && !seen[c]; c = c.parent {
+		if i, ok := index[c]; ok {
+			path := append([]*Chunk(nil), up[:i+1]...)
+			for j := len(down) - 1; j >= 0; j-- {
+				path = append(path, down[j])
+			}
+			return path
+		}
+		seen[c] = true
+		down = append(down, c)
+	}
+	return nil
+}
+
+// Parent returns the chunk c depends on, or nil for the root.
+func (c *Chunk) Parent() *Chunk {
+	return c.parent
+}
+
+// Children returns the chunks that depend on c, in sentence order.
+func (c *Chunk) Children() []*Chunk {
+	return c.children
+}
+
+// Ancestors returns the chain of chunks from c's parent up to the root.
+// Malformed links that form a cycle end the chain.
+func (c *Chunk) Ancestors() []*Chunk {
+	var ancestors []*Chunk
+	seen := map[*Chunk]bool{c: true}
+	for p := c.parent; p != nil && !seen[p]; p = p.parent {
+		seen[p] = true
+		ancestors = append(ancestors, p)
+	}
+	return ancestors
+}
+
+// Depth returns the number of links between c and the root.
+func (c *Chunk) Depth() int {
+	return len(c.Ancestors())
+}
+
+// Subtree returns c and every chunk that depends on it directly or
+// indirectly, in sentence order.
+func (c *Chunk) Subtree() []*Chunk {
+	in := map[*Chunk]bool{c: true}
+	queue := []*Chunk{c}
+	for len(queue) > 0 {
+		for _, child := range queue[0].children {
+			if !in[child] {
+				in[child] = true
+				queue = append(queue, child)
+			}
+		}
+		queue = queue[1:]
+	}
+	if c.sentence == nil {
+		return []*Chunk{c}
+	}
+	var subtree []*Chunk
+	for _, other := range c.sentence.Chunks {
+		if in[other] {
+			subtree = append(subtree, other)
+		}
+	}
+	return subtree
+}
+
+// Span returns the rune offsets covered by the tokens of c's subtree.
+func (c *Chunk) Span() (begin, end int) {
+	first := true
+	for _, other := range c.Subtree() {
+		for _, t := range other.Tokens {
+			if first || t.Begin < begin {
+				begin = t.Begin
+			}
+			if first || t.End > end {
+				end = t.End
+			}
+			first = false
+		}
+	}
+	return begin, end
+}
+
+// HeadToken returns the head (content) token of c, or nil if Head is out
+// of range.
+func (c *Chunk) HeadToken() *Token {
+	return c.token(c.Head)
+}
+
+// FuncToken returns the function token of c, or nil if Tail is out of
+// range.
+func (c *Chunk) FuncToken() *Token {
+	return c.token(c.Tail)
+}
+
+func (c *Chunk) token(i int64) *Token {
+	if i < 0 || i >= int64(len(c.Tokens)) {
+		return nil
+	}
+	return c.Tokens[i]
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"testing"
+)
+
+// 太郎は花子が読んでいる本を次郎に渡した
+var latticeTree = `* 0 5D 0/1 0.000000
+太郎	名詞,固有名詞,人名,名,*,*	O
+は	助詞,係助詞,*,*,*,*	O
+* 1 2D 0/1 0.000000
+花子	名詞,固有名詞,人名,名,*,*	O
+が	助詞,格助詞,*,*,*,*	O
+* 2 3D 0/2 0.000000
+読ん	動詞,一般,*,*,五段-マ行,連用形-撥音便	O
+で	助詞,接続助詞,*,*,*,*	O
+いる	動詞,非自立可能,*,*,上一段-ア行,連体形-一般	O
+* 3 5D 0/1 0.000000
+本	名詞,普通名詞,一般,*,*,*	O
+を	助詞,格助詞,*,*,*,*	O
+* 4 5D 0/1 0.000000
+次郎	名詞,固有名詞,人名,名,*,*	O
+に	助詞,格助詞,*,*,*,*	O
+* 5 -1D 0/1 0.000000
+渡し	動詞,一般,*,*,五段-サ行,連用形-一般	O
+た	助動詞,*,*,*,助動詞-タ,終止形-一般	O
+EOS
+`
+
+func chunkIds(chunks []*Chunk) []int64 {
+	ids := []int64{}
+	for _, c := range chunks {
+		ids = append(ids, c.Id)
+	}
+	return ids
+}
+
+func TestSentenceGraph(t *testing.T) {
+	s := NewSentence(latticeTree)
+	c := s.Chunks
+	expect := func(name string, output, expected interface{}) {
+		t.Helper()
+		if o, e := fmt.Sprint(output), fmt.Sprint(expected); o != e {
+			t.Errorf("%s: expected %s got %s", name, e, o)
+		}
+	}
+
+	expect("Root", s.Root().Id, int64(5))
+	expect("Parent", c[1].Parent().Id, int64(2))
+	expect("Root.Parent", s.Root().Parent() == nil, true)
+	expect("Children", chunkIds(c[5].Children()), []int64{0, 3, 4})
+	expect("Ancestors", chunkIds(c[1].Ancestors()), []int64{2, 3, 5})
+	expect("Depth", c[1].Depth(), 3)
+	expect("Subtree", chunkIds(c[3].Subtree()), []int64{1, 2, 3})
+	expect("PathBetween", chunkIds(s.PathBetween(c[1], c[4])), []int64{1, 2, 3, 5, 4})
+	expect("PathBetween self", chunkIds(s.PathBetween(c[2], c[2])), []int64{2})
+	expect("HeadToken", c[2].HeadToken().Surface(), "読ん")
+	expect("FuncToken", c[2].FuncToken().Surface(), "いる")
+
+	begin, end := c[3].Span()
+	expect("Span", []int{begin, end}, []int{3, 13})
+}
+
+func TestSentenceGraphCycle(t *testing.T) {
+	s := NewSentence("* 0 1D 0/0 0.000000\n本\t名詞,普通名詞,一般,*,*,*\tO\n* 1 0D 0/0 0.000000\nを\t助詞,格助詞,*,*,*,*\tO\nEOS\n")
+	if s.Root() != nil {
+		t.Errorf("expected no root")
+	}
+	if ids := chunkIds(s.Chunks[0].Ancestors()); len(ids) != 1 {
+		t.Errorf("Ancestors: expected [1] got %v", ids)
+	}
+	if ids := chunkIds(s.Chunks[0].Subtree()); len(ids) != 2 {
+		t.Errorf("Subtree: expected [0 1] got %v", ids)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// JSONVersion is the version of the JSON document format written by
+// MarshalJSON. The format is described by sentence.schema.json.
+const JSONVersion = 1
+
+// The JSON document format of a Sentence.
+type jsonSentence struct {
+	Version  int            `json:"version"`
+	ID       string         `json:"id,omitempty"`
+	Text     string         `json:"text"`
+	Schema   string         `json:"schema"` // FeatureSchema name
+	Comments []string       `json:"comments,omitempty"`
+	Warnings []*DecodeError `json:"warnings,omitempty"`
+	Chunks   []jsonChunk    `json:"chunks"`
+}
+
+type jsonChunk struct {
+	*Chunk
+	Tokens []jsonToken `json:"tokens"`
+}
+
+// Tokens carry their surface, which is not always their Orth.
+type jsonToken struct {
+	Surface string `json:"surface"`
+	*Token
+}
+
+// MarshalJSON encodes s as a compact JSON document of the current
+// JSONVersion.
+func (s Sentence) MarshalJSON() ([]byte, error) {
+	schema := s.schema
+	if schema == nil {
+		schema = UniDic21
+	}
+	doc := jsonSentence{
+		Version:  JSONVersion,
+		ID:       s.ID,
+		Text:     s.Text(),
+		Schema:   schema.Name(),
+		Comments: s.Comments,
+		Warnings: s.Warnings,
+		Chunks:   make([]jsonChunk, len(s.Chunks)),
+	}
+	for i, c := range s.Chunks {
+		jc := jsonChunk{Chunk: c, Tokens: make([]jsonToken, len(c.Tokens))}
+		for j, t := range c.Tokens {
+			jc.Tokens[j] = jsonToken{Surface: t.Surface(), Token: t}
+		}
+		doc.Chunks[i] = jc
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes a JSON document written by MarshalJSON, or the bare
+// chunk array written by ToJSON. Token fields are taken as they are, not
+// recomputed from their features. A schema name that LookupSchema does not
+// know is kept, and the raw features of its tokens are then written back
+// unchanged by ToLattice and ToXML.
+func (s *Sentence) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		var chunks []*Chunk
+		if err := json.Unmarshal(data, &chunks); err != nil {
+			return err
+		}
+		*s = Sentence{Chunks: chunks}
+		s.Resolve()
+		return nil
+	}
+
+	var doc jsonSentence
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Version < 1 || doc.Version > JSONVersion {
+		return fmt.Errorf("cabocha: unsupported JSON document version %d", doc.Version)
+	}
+	*s = Sentence{
+		ID:       doc.ID,
+		Comments: doc.Comments,
+		Warnings: doc.Warnings,
+		schema:   LookupSchema(doc.Schema),
+		text:     doc.Text,
+	}
+	if s.schema == nil && doc.Schema != "" {
+		s.schema = unknownSchema(doc.Schema)
+	}
+	for _, jc := range doc.Chunks {
+		c := jc.Chunk
+		if c == nil {
+			c = new(Chunk)
+		}
+		c.Tokens = nil
+		for _, jt := range jc.Tokens {
+			t := jt.Token
+			if t == nil {
+				t = new(Token)
+			}
+			t.surface = jt.Surface
+			t.schema = s.schema
+			c.Tokens = append(c.Tokens, t)
+		}
+		s.Chunks = append(s.Chunks, c)
+	}
+	s.Resolve()
+	return nil
+}
+
+// ToJSONDocument returns s as a JSON document (see MarshalJSON), compact if
+// indent is empty and indented by indent per level otherwise.
+func (s Sentence) ToJSONDocument(indent string) []byte {
+	var (
+		out []byte
+		err error
+	)
+	if indent == "" {
+		out, err = json.Marshal(s)
+	} else {
+		out, err = json.MarshalIndent(s, "", indent)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return out
+}
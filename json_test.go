@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSentenceJSONRoundTrip(t *testing.T) {
+	for _, lattice := range []string{outputCorrect, latticeQuoted, latticeEscapedQuote, latticeIPADIC, "# S-ID:7\n" + latticeTree} {
+		s := NewSentence(lattice)
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Sentence
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if output := decoded.ToLattice(); output != lattice {
+			t.Errorf("Echo: expected %q got %q", lattice, output)
+		}
+		if decoded.ID != s.ID || decoded.schema != s.schema {
+			t.Errorf("expected ID %q and schema %v, got %q and %v", s.ID, s.schema, decoded.ID, decoded.schema)
+		}
+		if again, _ := json.Marshal(decoded); !bytes.Equal(again, data) {
+			t.Errorf("Echo: expected %s got %s", data, again)
+		}
+		if len(decoded.Chunks) > 0 && decoded.Chunks[0].sentence != &decoded {
+			t.Errorf("chunks not resolved")
+		}
+	}
+}
+
+func TestSentenceJSONDocument(t *testing.T) {
+	s := NewSentence("# S-ID:7\n" + latticeTree)
+	var doc map[string]interface{}
+	if err := json.Unmarshal(s.ToJSONDocument(""), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]interface{}{
+		"version": float64(JSONVersion),
+		"id":      "7",
+		"text":    "太郎は花子が読んでいる本を次郎に渡した",
+		"schema":  "unidic-2.1",
+	} {
+		if doc[key] != expected {
+			t.Errorf("%s: expected %v got %v", key, expected, doc[key])
+		}
+	}
+
+	compact, indented := s.ToJSONDocument(""), s.ToJSONDocument("  ")
+	if bytes.Contains(compact, []byte("\n")) || !bytes.Contains(indented, []byte("\n  \"version\": 1,")) {
+		t.Errorf("unexpected encodings:\n%s\n%s", compact, indented)
+	}
+	var buf bytes.Buffer
+	json.Compact(&buf, indented)
+	if !bytes.Equal(buf.Bytes(), compact) {
+		t.Errorf("indented document differs from compact one")
+	}
+}
+
+func TestSentenceUnmarshalLegacyJSON(t *testing.T) {
+	var s Sentence
+	if err := json.Unmarshal(outputCorrectJSON, &s); err != nil {
+		t.Fatal(err)
+	}
+	if output := s.ToJSON(); !bytes.Equal(output, outputCorrectJSON) {
+		t.Errorf("Echo: expected %q got %q", outputCorrectJSON, output)
+	}
+	if surface := s.Chunks[0].Tokens[2].Surface(); surface != "未知" {
+		t.Errorf("expected surface 未知, got %q", surface)
+	}
+}
+
+func TestSentenceUnmarshalJSONVersion(t *testing.T) {
+	var s Sentence
+	err := json.Unmarshal([]byte(`{"version":2,"chunks":[]}`), &s)
+	if err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestSentenceJSONCustomSchema(t *testing.T) {
+	lattice := "* 0 -1D 0/0 0.000000\n語\t名詞,一般,ゴ,ゴゴ\tO\nEOS\n"
+	custom := func(name string) FeatureSchema {
+		return &columnSchema{
+			name:     name,
+			columns:  []string{"pos1", "pos2", "reading", "pronunciation"},
+			targets:  map[string][]string{"reading": {"kana"}, "pronunciation": {"pron"}},
+			required: 4,
+			unknown:  2,
+		}
+	}
+	roundTrip := func(schema FeatureSchema) *Sentence {
+		s, err := DecodeSentence(lattice, DecodeOptions{Schema: schema})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded Sentence
+		if err := json.Unmarshal(s.ToJSONDocument(""), &loaded); err != nil {
+			t.Fatal(err)
+		}
+		if output := loaded.Schema().Name(); output != schema.Name() {
+			t.Errorf("Schema: expected %q got %q", schema.Name(), output)
+		}
+		return &loaded
+	}
+
+	// Unregistered: the raw features are kept as they are.
+	loaded := roundTrip(custom("test-unregistered"))
+	loaded.Tokens()[0].Lemma = "ご"
+	if output := loaded.ToLattice(); output != lattice {
+		t.Errorf("Echo: expected %q got %q", lattice, output)
+	}
+
+	// Registered: the schema itself comes back.
+	schema := LookupSchema("test-registered") // registered by an earlier -count run
+	if schema == nil {
+		schema = custom("test-registered")
+		if err := RegisterSchema(schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := RegisterSchema(custom("test-registered")); err == nil {
+		t.Errorf("expected an error registering %q twice", schema.Name())
+	}
+	loaded = roundTrip(schema)
+	if loaded.Schema() != schema {
+		t.Errorf("expected the registered schema back")
+	}
+	if output, ok := loaded.Tokens()[0].Feature("reading"); output != "ゴ" || !ok {
+		t.Errorf("Feature(reading): expected %q got %q, %v", "ゴ", output, ok)
+	}
+	loaded.Tokens()[0].Kana = "ゴウ"
+	expected := strings.Replace(lattice, "ゴ,ゴゴ", "ゴウ,ゴゴ", 1)
+	if output := loaded.ToLattice(); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+}
@@ -29,46 +29,73 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 */
 package natsume_cabocha_bindings
 
-// #cgo LDFLAGS: -lcabocha
-// #include <stdio.h>
-// #include <stdlib.h>
-// #include <cabocha.h>
-// struct cabocha_t {};
-import "C"
-
 import (
-	"encoding/csv"
+	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"log"
 	re "regexp"
-	"strconv"
 	"strings"
-	"unicode/utf8"
 )
 
 // Token struct containing named strings for UniDic features.
 type Token struct {
-	Begin    int    `xml:"begin,attr" json:"begin"`
-	End      int    `xml:"end,attr" json:"end"`
-	Pos1     string `xml:"pos1" json:"pos1"`
-	Pos2     string `xml:"pos2" json:"pos2"`
-	Pos3     string `xml:"pos3" json:"pos3"`
-	Pos4     string `xml:"pos4" json:"pos4"`
-	CType    string `xml:"cType" json:"cType"`
-	CForm    string `xml:"cForm" json:"cForm"`
-	LForm    string `xml:"lForm" json:"lForm"`
-	Lemma    string `xml:"lemma" json:"lemma"`
-	Orth     string `xml:"orth" json:"orth"`
-	Pron     string `xml:"pron" json:"pron"`
-	OrthBase string `xml:"orthBase" json:"orthBase"`
-	PronBase string `xml:"pronBase" json:"pronBase"`
-	Goshu    string `xml:"goshu" json:"goshu"`
-	IType    string `xml:"iType" json:"iType"`
-	IForm    string `xml:"iForm" json:"iForm"`
-	FType    string `xml:"fType" json:"fType"`
-	FForm    string `xml:"fForm" json:"fForm"`
-	Ne       string `xml:"ne,attr" json:"ne"`
+	Begin     int    `xml:"begin,attr" json:"begin"`         // in runes
+	End       int    `xml:"end,attr" json:"end"`             // in runes
+	ByteBegin int    `xml:"byteBegin,attr" json:"byteBegin"` // in bytes
+	ByteEnd   int    `xml:"byteEnd,attr" json:"byteEnd"`     // in bytes
+	Pos1      string `xml:"pos1" json:"pos1"`
+	Pos2      string `xml:"pos2" json:"pos2"`
+	Pos3      string `xml:"pos3" json:"pos3"`
+	Pos4      string `xml:"pos4" json:"pos4"`
+	CType     string `xml:"cType" json:"cType"`
+	CForm     string `xml:"cForm" json:"cForm"`
+	LForm     string `xml:"lForm" json:"lForm"`
+	Lemma     string `xml:"lemma" json:"lemma"`
+	Orth      string `xml:"orth" json:"orth"`
+	Pron      string `xml:"pron" json:"pron"`
+	Kana      string `xml:"kana" json:"kana"`
+	Goshu     string `xml:"goshu" json:"goshu"`
+	OrthBase  string `xml:"orthBase" json:"orthBase"`
+	PronBase  string `xml:"pronBase" json:"pronBase"`
+	KanaBase  string `xml:"kanaBase" json:"kanaBase"`
+	FormBase  string `xml:"formBase" json:"formBase"`
+	IType     string `xml:"iType" json:"iType"`
+	IForm     string `xml:"iForm" json:"iForm"`
+	IConType  string `xml:"iConType" json:"iConType"`
+	FType     string `xml:"fType" json:"fType"`
+	FForm     string `xml:"fForm" json:"fForm"`
+	FConType  string `xml:"fConType" json:"fConType"`
+	AType     string `xml:"aType" json:"aType"`
+	AConType  string `xml:"aConType" json:"aConType"`
+	AModType  string `xml:"aModType" json:"aModType"`
+	Type      string `xml:"type,omitempty" json:"type,omitempty"`       // UniDic 2.3 and later
+	Form      string `xml:"form,omitempty" json:"form,omitempty"`       // UniDic 2.3 and later
+	LID       string `xml:"lid,omitempty" json:"lid,omitempty"`         // UniDic 2.2 and later
+	LemmaID   string `xml:"lemmaId,omitempty" json:"lemmaId,omitempty"` // UniDic 2.2 and later
+	Ne        string `xml:"ne,attr" json:"ne"`
+	Unknown   bool   `xml:"unknown,attr,omitempty" json:"unknown,omitempty"` // not in the dictionary
+
+	// Features holds the raw feature columns as decoded, including any
+	// the schema does not name (e.g. from a user dictionary).
+	Features []string `xml:"features>feature,omitempty" json:"features,omitempty"`
+
+	// UTF16 holds the offsets in UTF-16 code units, as used by
+	// JavaScript, if requested with DecodeOptions.UTF16.
+	UTF16 *Span `xml:"-" json:"utf16,omitempty"`
+
+	surface string // as it appeared in the input
+	schema  FeatureSchema
+}
+
+// Surface returns the token as it appeared in the input, falling back to
+// Orth for tokens that were not decoded from CaboCha output.
+func (t *Token) Surface() string {
+	if t.surface != "" {
+		return t.surface
+	}
+	return t.Orth
 }
 
 type Chunk struct {
@@ -78,135 +105,195 @@ type Chunk struct {
 	Head   int64    `xml:"head,attr" json:"head"`
 	Tail   int64    `xml:"func,attr" json:"tail"`
 	Tokens []*Token `json:"tokens"`
+
+	sentence *Sentence
+	parent   *Chunk
+	children []*Chunk
 }
 
 // Sentence struct type wrapper for slice of Chunk structs.
 type Sentence struct {
-	Chunks []*Chunk `json:"chunks"` // TODO in the JSON output,
+	ID       string         `json:"id,omitempty" xml:"-"`       // from a "# S-ID:" or "# sent_id =" comment
+	Comments []string       `json:"comments,omitempty" xml:"-"` // "#" comment lines, verbatim
+	Warnings []*DecodeError `json:"warnings,omitempty" xml:"-"` // malformed input skipped in lenient mode
+	Chunks   []*Chunk       `json:"chunks"`                     // TODO in the JSON output,
 	// this seems slightly
 	// unneeded, an array would do
 	// fine as well.
+
+	schema FeatureSchema
+	text   string // the input, if parsed rather than decoded
 }
 
+// TokenXML is a <tok> element of CaboCha's XML output (-f3).
 type TokenXML struct {
 	XMLName  xml.Name `xml:"tok"`
-	Id       int      `xml:"id,attr"`
+	Id       int      `xml:"id,attr"` // position in the sentence
 	Features string   `xml:"feature,attr"`
-	Ne       string   `xml:"ne,attr"`
+	Ne       string   `xml:"ne,attr,omitempty"`
 	Orth     string   `xml:",chardata"`
-	// Orth is included in Features.
+	// Orth is the surface form; Features does not repeat it.
 }
 
+// ChunkXML is a <chunk> element of CaboCha's XML output (-f3).
 type ChunkXML struct {
 	XMLName xml.Name    `xml:"chunk"`
 	Id      int64       `xml:"id,attr"`
 	Link    int64       `xml:"link,attr"`
-	Rel     string      `xml:"rel,attr"`
+	Rel     string      `xml:"rel,attr"` // always "D"
 	Prob    float64     `xml:"score,attr"`
 	Head    int64       `xml:"head,attr"`
 	Tail    int64       `xml:"func,attr"`
-	Tokens  []*TokenXML // `xml:"tok"`
+	Tokens  []*TokenXML `xml:"tok"`
 }
 
-// Sentence struct type wrapper for slice of Token structs.
+// SentenceXML is the <sentence> element of CaboCha's XML output (-f3).
 type SentenceXML struct {
-	XMLName xml.Name `xml:"sentence"`
-	//Chunks  []*Chunk// `xml:"chunk"`
-	Data string `xml:",innerxml"`
+	XMLName xml.Name    `xml:"sentence"`
+	Chunks  []*ChunkXML `xml:"chunk"`
 }
 
+// Returns a new Chunk from a "* id linkD head/func score" header line.
+// Fields that cannot be decoded are left at their zero value.
 func NewChunk(s string) *Chunk {
-	fields := strings.Split(s, " ")
-	head_tail := strings.Split(fields[3], "/")
-	c := new(Chunk)
-	c.Id, _ = strconv.ParseInt(fields[1], 10, 64)
-	c.Link, _ = strconv.ParseInt(strings.Replace(fields[2], "D", "", 1), 10, 64)
-	c.Head, _ = strconv.ParseInt(head_tail[0], 10, 64)
-	c.Tail, _ = strconv.ParseInt(head_tail[1], 10, 64)
-	c.Prob, _ = strconv.ParseFloat(fields[4], 64)
+	c, _ := parseChunk(s)
 	return c
 }
 
 var chunkHeaderRe = re.MustCompile(`^\*[^\t]+$`)
 
-// Takes the CaboCha output of one sentence as a string and returns a pointer to the corresponding Sentence struct.
-// CaboCha output should comprise one (un-split) sentence only.
-func NewSentence(cabocha_out string) *Sentence {
-	mecab_lines := strings.Split(cabocha_out, "\n")
-	s := new(Sentence)
-	c := new(Chunk)
-	i := 0
-	for _, line := range mecab_lines {
-		if chunkHeaderRe.MatchString(line) { // New chunk
-			if len(c.Tokens) != 0 {
-				s.Chunks = append(s.Chunks, c)
-			}
-			c = NewChunk(line)
-			continue
-		} else if line == "EOS" || line == "" { // End
-			s.Chunks = append(s.Chunks, c)
-			break // TODO NewSentence must be called on only one sentence
+// Returns a new Token for the given surface string, feature list and
+// named entity tag. The named feature fields are filled in once the
+// sentence's FeatureSchema is known, and the offsets by place.
+func newToken(surface string, features []string, ne string) *Token {
+	return &Token{
+		Ne:       ne,
+		Features: features,
+		surface:  surface,
+	}
+}
+
+// An offset into a string, counted in runes, bytes and UTF-16 code units.
+type position struct {
+	runes, bytes, utf16 int
+}
+
+// Returns p moved past s.
+func (p position) after(s string) position {
+	p.bytes += len(s)
+	for _, r := range s {
+		p.runes++
+		p.utf16++
+		if r >= 0x10000 {
+			p.utf16++ // surrogate pair
 		}
+	}
+	return p
+}
+
+// Sets the offsets of t to run from begin over its surface string, and
+// returns where it ends. UTF-16 offsets are only set if withUTF16 is.
+func (t *Token) place(begin position, withUTF16 bool) position {
+	end := begin.after(t.Surface())
+	t.Begin, t.End = begin.runes, end.runes
+	t.ByteBegin, t.ByteEnd = begin.bytes, end.bytes
+	t.UTF16 = nil
+	if withUTF16 {
+		t.UTF16 = &Span{begin.utf16, end.utf16}
+	}
+	return end
+}
 
-		fields := strings.Split(line, "\t")
-		if len(fields) != 3 {
-			log.Println("Error decoding token features:", fields)
+// Feature returns the raw feature column with the given name in the
+// token's schema, e.g. "aType" for UniDic or "reading" for IPADIC. Tokens
+// without raw features fall back to the named field of the same name.
+func (t *Token) Feature(column string) (string, bool) {
+	schema := t.schema
+	if schema == nil {
+		schema = UniDic21
+	}
+	for i, name := range schema.Columns() {
+		if name != column {
 			continue
 		}
-
-		csvReader := csv.NewReader(strings.NewReader(fields[1]))
-		featuresSlice, err := csvReader.Read()
-		if err != nil {
-			log.Println("Error decoding feature csv field:", err)
+		if t.Features != nil {
+			if features := t.featureList(); i < len(features) {
+				return features[i], true
+			}
+			return "", false
+		}
+		break
+	}
+	if t.Features == nil {
+		if field := t.field(column); field != nil {
+			return *field, true
 		}
+	}
+	return "", false
+}
+
+// Extra returns the raw feature columns beyond those named by the token's
+// schema, such as custom user dictionary columns.
+func (t *Token) Extra() []string {
+	schema := t.schema
+	if schema == nil {
+		schema = UniDic21
+	}
+	if n := len(schema.Columns()); len(t.Features) > n {
+		return t.Features[n:]
+	}
+	return nil
+}
 
-		end := i + utf8.RuneCountInString(fields[0])
-
-		if len(featuresSlice) == 6 {
-			c.Tokens = append(c.Tokens, &Token{
-				Begin:    i,
-				End:      end,
-				Pos1:     featuresSlice[0],
-				Pos2:     featuresSlice[1],
-				Pos3:     featuresSlice[2],
-				Pos4:     featuresSlice[3],
-				CType:    featuresSlice[4],
-				CForm:    featuresSlice[5],
-				Orth:     fields[0],
-				Lemma:    fields[0],
-				OrthBase: fields[0],
-				Goshu:    "不明",
-				Ne:       fields[2]})
-		} else {
-			c.Tokens = append(c.Tokens, &Token{
-				Begin:    i,
-				End:      end,
-				Pos1:     featuresSlice[0],
-				Pos2:     featuresSlice[1],
-				Pos3:     featuresSlice[2],
-				Pos4:     featuresSlice[3],
-				CType:    featuresSlice[4],
-				CForm:    featuresSlice[5],
-				LForm:    featuresSlice[6],
-				Lemma:    featuresSlice[7],
-				Orth:     featuresSlice[8],
-				Pron:     featuresSlice[9],
-				OrthBase: featuresSlice[10],
-				PronBase: featuresSlice[11],
-				Goshu:    featuresSlice[12],
-				IType:    featuresSlice[13],
-				IForm:    featuresSlice[14],
-				FType:    featuresSlice[15],
-				FForm:    featuresSlice[16],
-				Ne:       fields[2]})
+// Returns the feature list of t. Columns whose named field was changed
+// since t was decoded are rebuilt from the field; the others, and any
+// columns the schema does not name, are kept as decoded. Tokens without
+// raw features are rebuilt entirely. Without a schema, raw features are
+// only rebuilt if DetectSchema recognizes them.
+func (t *Token) featureList() []string {
+	schema := t.schema
+	if schema == nil {
+		schema = DetectSchema(t.Features)
+	}
+	if schema == nil {
+		if t.Features != nil {
+			return t.Features
 		}
-		i = end
+		schema = UniDic21
 	}
-	return s
+	rebuilt := schema.Features(t)
+	if t.Features == nil {
+		return rebuilt
+	}
+	decoded := &Token{Features: t.Features, surface: t.surface}
+	schema.Apply(decoded, t.Features)
+	original := schema.Features(decoded)
+	if len(original) != len(rebuilt) {
+		// Columns were added or dropped, e.g. by setting LID.
+		return append(rebuilt, t.Extra()...)
+	}
+	var features []string
+	for i := range rebuilt {
+		if rebuilt[i] == original[i] || i >= len(t.Features) {
+			continue
+		}
+		if features == nil {
+			features = append([]string(nil), t.Features...)
+		}
+		features[i] = rebuilt[i]
+	}
+	if features == nil {
+		return t.Features
+	}
+	return features
 }
 
-func NewParser(opt string) *C.cabocha_t {
-	return C.cabocha_new2(C.CString(opt))
+// Takes the CaboCha output of one sentence as a string and returns a pointer to the corresponding Sentence struct.
+// Decoding stops at the first EOS; use a Decoder for multi-sentence output.
+// Malformed lines are skipped and reported in Sentence.Warnings.
+func NewSentence(cabocha_out string) *Sentence {
+	s, _ := DecodeSentence(cabocha_out, DecodeOptions{})
+	return s
 }
 
 const (
@@ -217,23 +304,66 @@ const (
 	FormatNone
 )
 
-func ParseToFormat(cabo *C.cabocha_t, s string, format _Ctype_int) string {
-	tree := C.cabocha_sparse_totree(cabo, C.CString(s))
-	return C.GoString(C.cabocha_tree_tostr(tree, format))
-}
-
 // Convenience function that returns the CaboCha output as a Sentence
-// struct.
+// struct. Errors are logged and an empty Sentence is returned; use Parse to
+// handle them.
 func ParseToSentence(s string) *Sentence {
-	return NewSentence(ParseToFormat(Parser, s, FormatLattice))
+	sentence, err := Parse(context.Background(), s)
+	if err != nil {
+		log.Println("Error parsing sentence:", err)
+		return new(Sentence)
+	}
+	return sentence
 }
 
 // Convenience function that returns the CaboCha output as a lattice
-// formatted string.
+// formatted string. Errors are logged and an empty string is returned; use
+// ParseString to handle them.
 func ParseToLatticeString(s string) string {
-	return ParseToFormat(Parser, s, FormatLattice)
+	out, err := ParseString(context.Background(), s, FormatLattice)
+	if err != nil {
+		log.Println("Error parsing sentence:", err)
+	}
+	return out
+}
+
+// Tokens returns the tokens of all chunks in order.
+func (s *Sentence) Tokens() []*Token {
+	var tokens []*Token
+	for _, c := range s.Chunks {
+		tokens = append(tokens, c.Tokens...)
+	}
+	return tokens
+}
+
+// Text returns the input s was parsed from, including any whitespace that
+// MeCab dropped. Sentences decoded from CaboCha output do not keep their
+// input, so for them the text is rebuilt from the tokens, with a space
+// for every rune of a gap between their offsets.
+func (s *Sentence) Text() string {
+	if s.text != "" {
+		return s.text
+	}
+	return surfaceText(s.Tokens())
 }
 
+// Returns the sentence text, with spaces wherever the offsets of
+// neighbouring tokens leave a gap, so that each token starts Begin minus
+// the first token's Begin runes into it.
+func surfaceText(tokens []*Token) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.Begin > tokens[i-1].End {
+			b.WriteString(strings.Repeat(" ", t.Begin-tokens[i-1].End))
+		}
+		b.WriteString(t.Surface())
+	}
+	return b.String()
+}
+
+// ToJSON returns the chunks of s as an indented JSON array, the format of
+// earlier versions of this package. Use ToJSONDocument for the versioned
+// document format, which keeps the sentence ID, text and schema.
 func (s Sentence) ToJSON() []byte {
 	jsonSentence, err := json.MarshalIndent(s.Chunks, "", "  ")
 	if err != nil {
@@ -242,13 +372,10 @@ func (s Sentence) ToJSON() []byte {
 	return []byte(jsonSentence)
 }
 
+// ToXML returns s in CaboCha's XML format (-f3), byte-for-byte as
+// CaboCha would print it.
 func (s Sentence) ToXML() []byte {
-	xmlSentence, err := xml.MarshalIndent(s, "", "  ")
-	if err != nil {
-		log.Println(err)
-	}
-	return []byte(xmlSentence)
+	var b bytes.Buffer
+	s.toSentenceXML().write(&b)
+	return b.Bytes()
 }
-
-// better way to instantiate only once?
-var Parser = NewParser("")
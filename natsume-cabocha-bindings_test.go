@@ -31,6 +31,7 @@ package natsume_cabocha_bindings
 
 import (
 	"bytes"
+	"context"
 	"testing"
 )
 
@@ -66,6 +67,25 @@ func BenchmarkParseToSentenceJSON(b *testing.B) {
 	}
 }
 
+func TestNewSentenceJSON(t *testing.T) {
+	output := NewSentence(outputCorrect).ToJSON()
+	if !bytes.Equal(output, outputCorrectJSON) {
+		t.Errorf("Echo: expected %q got %q", outputCorrectJSON, output)
+	}
+}
+
+func TestParseMatchesNewSentence(t *testing.T) {
+	sentence, err := Parse(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := sentence.ToJSON()
+	expected := NewSentence(outputCorrect).ToJSON()
+	if !bytes.Equal(output, expected) {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+}
+
 var input = "hello，未知語"
 var outputCorrect = `* 0 -1D 3/3 0.000000
 hello	名詞,普通名詞,一般,*,*,*	O
@@ -74,4 +94,4 @@ hello	名詞,普通名詞,一般,*,*,*	O
 語	名詞,普通名詞,一般,*,*,*,ゴ,語,語,ゴ,ゴ,漢,語,ゴ,ゴ,ゴ,*,*,*,*,*,*,1,C3,*	O
 EOS
 `
-var outputCorrectJSON = []byte("[\n  {\n    \"id\": 0,\n    \"link\": -1,\n    \"prob\": 0,\n    \"head\": 3,\n    \"tail\": 3,\n    \"tokens\": [\n      {\n        \"begin\": 0,\n        \"end\": 5,\n        \"pos1\": \"名詞\",\n        \"pos2\": \"普通名詞\",\n        \"pos3\": \"一般\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"\",\n        \"lemma\": \"hello\",\n        \"orth\": \"hello\",\n        \"pron\": \"\",\n        \"kana\": \"\",\n        \"goshu\": \"不明\",\n        \"orthBase\": \"hello\",\n        \"pronBase\": \"\",\n        \"kanaBase\": \"\",\n        \"formBase\": \"\",\n        \"iType\": \"\",\n        \"iForm\": \"\",\n        \"IConType\": \"\",\n        \"fType\": \"\",\n        \"fForm\": \"\",\n        \"fConType\": \"\",\n        \"aType\": \"\",\n        \"aConType\": \"\",\n        \"aModType\": \"\",\n        \"ne\": \"O\"\n      },\n      {\n        \"begin\": 5,\n        \"end\": 6,\n        \"pos1\": \"補助記号\",\n        \"pos2\": \"読点\",\n        \"pos3\": \"*\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"\",\n        \"lemma\": \"，\",\n        \"orth\": \"，\",\n        \"pron\": \"\",\n        \"kana\": \"\",\n        \"goshu\": \"記号\",\n        \"orthBase\": \"，\",\n        \"pronBase\": \"\",\n        \"kanaBase\": \"\",\n        \"formBase\": \"\",\n        \"iType\": \"*\",\n        \"iForm\": \"*\",\n        \"IConType\": \"*\",\n        \"fType\": \"*\",\n        \"fForm\": \"*\",\n        \"fConType\": \"*\",\n        \"aType\": \"*\",\n        \"aConType\": \"*\",\n        \"aModType\": \"*\",\n        \"ne\": \"O\"\n      },\n      {\n        \"begin\": 6,\n        \"end\": 8,\n        \"pos1\": \"名詞\",\n        \"pos2\": \"普通名詞\",\n        \"pos3\": \"形状詞可能\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"ミチ\",\n        \"lemma\": \"未知\",\n        \"orth\": \"未知\",\n        \"pron\": \"ミチ\",\n        \"kana\": \"ミチ\",\n        \"goshu\": \"漢\",\n        \"orthBase\": \"未知\",\n        \"pronBase\": \"ミチ\",\n        \"kanaBase\": \"ミチ\",\n        \"formBase\": \"ミチ\",\n        \"iType\": \"*\",\n        \"iForm\": \"*\",\n        \"IConType\": \"*\",\n        \"fType\": \"*\",\n        \"fForm\": \"*\",\n        \"fConType\": \"*\",\n        \"aType\": \"1\",\n        \"aConType\": \"C3\",\n        \"aModType\": \"*\",\n        \"ne\": \"O\"\n      },\n      {\n        \"begin\": 8,\n        \"end\": 9,\n        \"pos1\": \"名詞\",\n        \"pos2\": \"普通名詞\",\n        \"pos3\": \"一般\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"ゴ\",\n        \"lemma\": \"語\",\n        \"orth\": \"語\",\n        \"pron\": \"ゴ\",\n        \"kana\": \"ゴ\",\n        \"goshu\": \"漢\",\n        \"orthBase\": \"語\",\n        \"pronBase\": \"ゴ\",\n        \"kanaBase\": \"ゴ\",\n        \"formBase\": \"ゴ\",\n        \"iType\": \"*\",\n        \"iForm\": \"*\",\n        \"IConType\": \"*\",\n        \"fType\": \"*\",\n        \"fForm\": \"*\",\n        \"fConType\": \"*\",\n        \"aType\": \"1\",\n        \"aConType\": \"C3\",\n        \"aModType\": \"*\",\n        \"ne\": \"O\"\n      }\n    ]\n  }\n]")
+var outputCorrectJSON = []byte("[\n  {\n    \"id\": 0,\n    \"link\": -1,\n    \"prob\": 0,\n    \"head\": 3,\n    \"tail\": 3,\n    \"tokens\": [\n      {\n        \"begin\": 0,\n        \"end\": 5,\n        \"byteBegin\": 0,\n        \"byteEnd\": 5,\n        \"pos1\": \"名詞\",\n        \"pos2\": \"普通名詞\",\n        \"pos3\": \"一般\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"\",\n        \"lemma\": \"hello\",\n        \"orth\": \"hello\",\n        \"pron\": \"\",\n        \"kana\": \"\",\n        \"goshu\": \"不明\",\n        \"orthBase\": \"hello\",\n        \"pronBase\": \"\",\n        \"kanaBase\": \"\",\n        \"formBase\": \"\",\n        \"iType\": \"\",\n        \"iForm\": \"\",\n        \"iConType\": \"\",\n        \"fType\": \"\",\n        \"fForm\": \"\",\n        \"fConType\": \"\",\n        \"aType\": \"\",\n        \"aConType\": \"\",\n        \"aModType\": \"\",\n        \"ne\": \"O\",\n        \"unknown\": true,\n        \"features\": [\n          \"名詞\",\n          \"普通名詞\",\n          \"一般\",\n          \"*\",\n          \"*\",\n          \"*\"\n        ]\n      },\n      {\n        \"begin\": 5,\n        \"end\": 6,\n        \"byteBegin\": 5,\n        \"byteEnd\": 8,\n        \"pos1\": \"補助記号\",\n        \"pos2\": \"読点\",\n        \"pos3\": \"*\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"\",\n        \"lemma\": \"，\",\n        \"orth\": \"，\",\n        \"pron\": \"\",\n        \"kana\": \"\",\n        \"goshu\": \"記号\",\n        \"orthBase\": \"，\",\n        \"pronBase\": \"\",\n        \"kanaBase\": \"\",\n        \"formBase\": \"\",\n        \"iType\": \"*\",\n        \"iForm\": \"*\",\n        \"iConType\": \"*\",\n        \"fType\": \"*\",\n        \"fForm\": \"*\",\n        \"fConType\": \"*\",\n        \"aType\": \"*\",\n        \"aConType\": \"*\",\n        \"aModType\": \"*\",\n        \"ne\": \"O\",\n        \"features\": [\n          \"補助記号\",\n          \"読点\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"\",\n          \"，\",\n          \"，\",\n          \"\",\n          \"\",\n          \"記号\",\n          \"，\",\n          \"\",\n          \"\",\n          \"\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\"\n        ]\n      },\n      {\n        \"begin\": 6,\n        \"end\": 8,\n        \"byteBegin\": 8,\n        \"byteEnd\": 14,\n        \"pos1\": \"名詞\",\n        \"pos2\": \"普通名詞\",\n        \"pos3\": \"形状詞可能\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"ミチ\",\n        \"lemma\": \"未知\",\n        \"orth\": \"未知\",\n        \"pron\": \"ミチ\",\n        \"kana\": \"ミチ\",\n        \"goshu\": \"漢\",\n        \"orthBase\": \"未知\",\n        \"pronBase\": \"ミチ\",\n        \"kanaBase\": \"ミチ\",\n        \"formBase\": \"ミチ\",\n        \"iType\": \"*\",\n        \"iForm\": \"*\",\n        \"iConType\": \"*\",\n        \"fType\": \"*\",\n        \"fForm\": \"*\",\n        \"fConType\": \"*\",\n        \"aType\": \"1\",\n        \"aConType\": \"C3\",\n        \"aModType\": \"*\",\n        \"ne\": \"O\",\n        \"features\": [\n          \"名詞\",\n          \"普通名詞\",\n          \"形状詞可能\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"ミチ\",\n          \"未知\",\n          \"未知\",\n          \"ミチ\",\n          \"ミチ\",\n          \"漢\",\n          \"未知\",\n          \"ミチ\",\n          \"ミチ\",\n          \"ミチ\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"1\",\n          \"C3\",\n          \"*\"\n        ]\n      },\n      {\n        \"begin\": 8,\n        \"end\": 9,\n        \"byteBegin\": 14,\n        \"byteEnd\": 17,\n        \"pos1\": \"名詞\",\n        \"pos2\": \"普通名詞\",\n        \"pos3\": \"一般\",\n        \"pos4\": \"*\",\n        \"cType\": \"*\",\n        \"cForm\": \"*\",\n        \"lForm\": \"ゴ\",\n        \"lemma\": \"語\",\n        \"orth\": \"語\",\n        \"pron\": \"ゴ\",\n        \"kana\": \"ゴ\",\n        \"goshu\": \"漢\",\n        \"orthBase\": \"語\",\n        \"pronBase\": \"ゴ\",\n        \"kanaBase\": \"ゴ\",\n        \"formBase\": \"ゴ\",\n        \"iType\": \"*\",\n        \"iForm\": \"*\",\n        \"iConType\": \"*\",\n        \"fType\": \"*\",\n        \"fForm\": \"*\",\n        \"fConType\": \"*\",\n        \"aType\": \"1\",\n        \"aConType\": \"C3\",\n        \"aModType\": \"*\",\n        \"ne\": \"O\",\n        \"features\": [\n          \"名詞\",\n          \"普通名詞\",\n          \"一般\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"ゴ\",\n          \"語\",\n          \"語\",\n          \"ゴ\",\n          \"ゴ\",\n          \"漢\",\n          \"語\",\n          \"ゴ\",\n          \"ゴ\",\n          \"ゴ\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"*\",\n          \"1\",\n          \"C3\",\n          \"*\"\n        ]\n      }\n    ]\n  }\n]")
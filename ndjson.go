@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Record is one line of a JSON Lines stream carrying a sentence or a
+// document together with where it came from.
+type Record struct {
+	Source   string    `json:"source,omitempty"` // input file or other origin
+	Line     int       `json:"line,omitempty"`   // 1-based line in Source
+	ID       string    `json:"id,omitempty"`     // sentence ID
+	Sentence *Sentence `json:"sentence,omitempty"`
+	Document *Document `json:"document,omitempty"`
+}
+
+// NDJSONWriter writes sentences and documents as JSON Lines (NDJSON): one
+// compact JSON value per line, so that streams can be filtered with jq or
+// split between workers at any line boundary. Sentences are written in
+// the format of Sentence.MarshalJSON.
+type NDJSONWriter struct {
+	w *bufio.Writer
+}
+
+// Returns a new NDJSONWriter writing to w. Call Flush when done.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteSentence writes s on a line of its own.
+func (w *NDJSONWriter) WriteSentence(s *Sentence) error {
+	return w.write(s)
+}
+
+// WriteDocument writes d on a line of its own.
+func (w *NDJSONWriter) WriteDocument(d *Document) error {
+	return w.write(d)
+}
+
+// WriteRecord writes r, a sentence or document wrapped with its metadata,
+// on a line of its own. ID defaults to the ID of r.Sentence.
+func (w *NDJSONWriter) WriteRecord(r *Record) error {
+	if r.ID == "" && r.Sentence != nil {
+		rr := *r
+		rr.ID = r.Sentence.ID
+		r = &rr
+	}
+	return w.write(r)
+}
+
+func (w *NDJSONWriter) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.w.Write(data)
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes any buffered lines to the underlying writer.
+func (w *NDJSONWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// NDJSONReader reads JSON Lines written by an NDJSONWriter. Each line may
+// hold a bare sentence, a bare document or a Record; blank lines are
+// skipped.
+type NDJSONReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// Returns a new NDJSONReader reading from r.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return &NDJSONReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next line as a Record, or io.EOF when the input is
+// exhausted. Bare sentences and documents are returned in a Record with
+// only Sentence (and ID) or Document set. A line that cannot be decoded
+// yields a *DecodeError; reading may continue with the next call.
+func (r *NDJSONReader) Next() (*Record, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(line) == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		r.line++
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		rec, decodeErr := decodeRecord(line)
+		if decodeErr != nil {
+			return nil, &DecodeError{Line: r.line, Reason: decodeErr.Error()}
+		}
+		return rec, nil
+	}
+}
+
+// Decodes a line holding a Record, a Document or a Sentence, telling them
+// apart by their keys.
+func decodeRecord(line []byte) (*Record, error) {
+	var probe struct {
+		Sentence  json.RawMessage `json:"sentence"`
+		Document  json.RawMessage `json:"document"`
+		Sentences json.RawMessage `json:"sentences"`
+	}
+	if line[0] == '{' {
+		if err := json.Unmarshal(line, &probe); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case probe.Sentence != nil || probe.Document != nil:
+		rec := new(Record)
+		if err := json.Unmarshal(line, rec); err != nil {
+			return nil, err
+		}
+		return rec, nil
+	case probe.Sentences != nil:
+		d := new(Document)
+		if err := json.Unmarshal(line, d); err != nil {
+			return nil, err
+		}
+		return &Record{Document: d}, nil
+	}
+	s := new(Sentence)
+	if err := json.Unmarshal(line, s); err != nil {
+		return nil, err
+	}
+	return &Record{ID: s.ID, Sentence: s}, nil
+}
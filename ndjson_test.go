@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	s1 := NewSentence("# S-ID:1\n" + outputCorrect)
+	s2 := NewSentence(latticeTree)
+	doc := &Document{Text: "太郎は花子が読んでいる本を次郎に渡した", Sentences: []*Sentence{s2}}
+
+	var out bytes.Buffer
+	w := NewNDJSONWriter(&out)
+	w.WriteSentence(s1)
+	w.WriteDocument(doc)
+	w.WriteRecord(&Record{Source: "corpus.txt", Line: 3, Sentence: s2})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 3 {
+		t.Fatalf("expected 3 lines, got %d:\n%s", lines, out.String())
+	}
+
+	r := NewNDJSONReader(strings.NewReader(out.String() + "\n"))
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.ID != "1" || rec.Sentence == nil || rec.Sentence.ToLattice() != "# S-ID:1\n"+outputCorrect {
+		t.Errorf("sentence: unexpected record %+v", rec)
+	}
+
+	rec, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Document == nil || rec.Document.Text != doc.Text || len(rec.Document.Sentences) != 1 ||
+		rec.Document.Sentences[0].ToLattice() != latticeTree {
+		t.Errorf("document: unexpected record %+v", rec)
+	}
+
+	rec, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Source != "corpus.txt" || rec.Line != 3 || rec.Sentence == nil || rec.Sentence.ToLattice() != latticeTree {
+		t.Errorf("record: unexpected record %+v", rec)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestNDJSONRecordID(t *testing.T) {
+	var out bytes.Buffer
+	w := NewNDJSONWriter(&out)
+	w.WriteRecord(&Record{Source: "a.txt", Sentence: NewSentence("# sent_id = s9\n" + latticeTree)})
+	w.Flush()
+	if !strings.HasPrefix(out.String(), `{"source":"a.txt","id":"s9","sentence":{`) {
+		t.Errorf("unexpected envelope %s", out.String())
+	}
+}
+
+func TestNDJSONReaderError(t *testing.T) {
+	var out bytes.Buffer
+	w := NewNDJSONWriter(&out)
+	w.WriteSentence(NewSentence(latticeTree))
+	w.Flush()
+
+	r := NewNDJSONReader(strings.NewReader("{\"version\":\n" + out.String()))
+	_, err := r.Next()
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 1 {
+		t.Fatalf("expected error at line 1, got %v", err)
+	}
+	if rec, err := r.Next(); err != nil || rec.Sentence == nil {
+		t.Errorf("expected reading to resume, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Posset names the part-of-speech set the CaboCha models were trained on.
+type Posset string
+
+const (
+	PossetUniDic Posset = "UNIDIC"
+	PossetIPA    Posset = "IPA"
+	PossetJUMAN  Posset = "JUMAN"
+)
+
+// NEMode selects CaboCha's named entity recognition mode (-n).
+type NEMode int
+
+const (
+	NENone            NEMode = iota // no named entity recognition
+	NEChunkConstraint               // named entities may not cross chunks
+	NENoConstraint                  // named entities without chunk constraint
+)
+
+// Options is the typed form of the CaboCha command-line options. The zero
+// value uses CaboCha's compiled-in defaults.
+type Options struct {
+	ParserModel  string // dependency parser model (-m)
+	ChunkerModel string // chunker model (-M)
+	NEModel      string // named entity model (-N)
+	MecabDicdir  string // MeCab system dictionary directory (-d)
+	MecabUserdic string // MeCab user dictionary (-u)
+	NE           NEMode // named entity mode (-n)
+	Charset      string // input/output charset (-t), e.g. "UTF-8" or "EUC-JP"
+	Posset       Posset // POS set of the models (-P)
+
+	// Decode is not passed to CaboCha; it controls how parses are turned
+	// into Sentences.
+	Decode DecodeOptions
+}
+
+var charsets = map[string]bool{
+	"UTF8": true, "UTF-8": true,
+	"EUC-JP": true, "EUCJP": true,
+	"SHIFT-JIS": true, "SHIFT_JIS": true, "SJIS": true, "CP932": true,
+}
+
+// Validate checks the options without calling CaboCha. Bad values are
+// reported as an *Error wrapping ErrInvalidOption, and missing model or
+// dictionary files as an *Error wrapping ErrModelLoad.
+func (o Options) Validate() error {
+	paths := []struct {
+		flag, path string
+	}{
+		{"parser-model", o.ParserModel},
+		{"chunker-model", o.ChunkerModel},
+		{"ne-model", o.NEModel},
+		{"mecab-dicdir", o.MecabDicdir},
+		{"mecab-userdic", o.MecabUserdic},
+	}
+	for _, p := range paths {
+		if p.path == "" {
+			continue
+		}
+		// cabocha_new2 splits its argument on whitespace without quoting.
+		if strings.ContainsAny(p.path, " \t\r\n") {
+			return optionError(ErrInvalidOption, "%s: path contains whitespace: %q", p.flag, p.path)
+		}
+		if _, err := os.Stat(p.path); err != nil {
+			return optionError(ErrModelLoad, "%s: %v", p.flag, err)
+		}
+	}
+	if o.NE < NENone || o.NE > NENoConstraint {
+		return optionError(ErrInvalidOption, "ne: mode must be 0, 1 or 2, got %d", o.NE)
+	}
+	if o.Charset != "" && !charsets[strings.ToUpper(o.Charset)] {
+		return optionError(ErrInvalidOption, "charset: unsupported charset %q", o.Charset)
+	}
+	switch Posset(strings.ToUpper(string(o.Posset))) {
+	case "", PossetUniDic, PossetIPA, PossetJUMAN:
+	default:
+		return optionError(ErrInvalidOption, "posset: unknown posset %q", o.Posset)
+	}
+	return nil
+}
+
+// String renders the options as a cabocha_new2 option string.
+func (o Options) String() string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" {
+			args = append(args, "--"+flag+"="+value)
+		}
+	}
+	add("parser-model", o.ParserModel)
+	add("chunker-model", o.ChunkerModel)
+	add("ne-model", o.NEModel)
+	add("mecab-dicdir", o.MecabDicdir)
+	add("mecab-userdic", o.MecabUserdic)
+	if o.NE != NENone {
+		add("ne", fmt.Sprint(int(o.NE)))
+	}
+	add("charset", o.Charset)
+	add("posset", strings.ToUpper(string(o.Posset)))
+	return strings.Join(args, " ")
+}
+
+func optionError(err error, format string, a ...interface{}) *Error {
+	return &Error{Op: "options", Msg: fmt.Sprintf(format, a...), Err: err}
+}
+
+// Returns the decode options, with the schema implied by Posset for IPA
+// and JUMAN if Decode.Schema is nil. UniDic versions differ in layout, so
+// for them the schema is left to auto-detection.
+func (o Options) decodeOptions() DecodeOptions {
+	d := o.Decode
+	if d.Schema == nil {
+		switch Posset(strings.ToUpper(string(o.Posset))) {
+		case PossetIPA:
+			d.Schema = IPADIC
+		case PossetJUMAN:
+			d.Schema = JUMAN
+		}
+	}
+	return d
+}
+
+// Returns a new Parser configured by o, which is validated first.
+func NewParserWithOptions(o Options) (*Parser, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	p, err := NewParser(o.String())
+	if err != nil {
+		return nil, err
+	}
+	p.decode = o.decodeOptions()
+	return p, nil
+}
+
+// Returns a new ParserPool of n Parsers configured by o, which is
+// validated first.
+func NewParserPoolWithOptions(n int, o Options) (*ParserPool, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return newParserPool(n, func() (*Parser, error) {
+		return NewParserWithOptions(o)
+	})
+}
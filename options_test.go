@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestOptionsString(t *testing.T) {
+	o := Options{
+		ParserModel: "/models/dep.unidic",
+		NE:          NEChunkConstraint,
+		Charset:     "UTF8",
+		Posset:      "unidic",
+	}
+	expected := "--parser-model=/models/dep.unidic --ne=1 --charset=UTF8 --posset=UNIDIC"
+	if output := o.String(); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+	if output := (Options{}).String(); output != "" {
+		t.Errorf("Echo: expected empty option string got %q", output)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "chunk.unidic")
+	if err := os.WriteFile(model, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		o    Options
+		want error
+	}{
+		{Options{}, nil},
+		{Options{ChunkerModel: model, Posset: PossetUniDic, Charset: "euc-jp"}, nil},
+		{Options{ChunkerModel: filepath.Join(dir, "missing")}, ErrModelLoad},
+		{Options{MecabDicdir: dir + "/with space"}, ErrInvalidOption},
+		{Options{NE: 3}, ErrInvalidOption},
+		{Options{Charset: "latin1"}, ErrInvalidOption},
+		{Options{Posset: "KNP"}, ErrInvalidOption},
+	}
+	for _, test := range tests {
+		err := test.o.Validate()
+		if test.want == nil && err != nil || !errors.Is(err, test.want) {
+			t.Errorf("%+v: expected %v got %v", test.o, test.want, err)
+		}
+	}
+}
+
+func TestParserWithOptions(t *testing.T) {
+	p, err := NewParserWithOptions(Options{NE: NEChunkConstraint, Charset: "UTF-8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+}
+
+func TestSetDefaultOptionsAfterInit(t *testing.T) {
+	if _, err := DefaultParser(); err != nil {
+		t.Skip(err)
+	}
+	if err := SetDefaultOptions(Options{NE: NEChunkConstraint}); err != ErrDefaultInitialized {
+		t.Errorf("expected %v got %v", ErrDefaultInitialized, err)
+	}
+}
+
+// Runs in a fresh process, so that no other test has touched the default
+// parser yet.
+func TestDefaultParserLazy(t *testing.T) {
+	if os.Getenv("CABOCHA_TEST_LAZY") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestDefaultParserLazy$")
+		cmd.Env = append(os.Environ(), "CABOCHA_TEST_LAZY=1")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v\n%s", err, output)
+		}
+		return
+	}
+
+	NewSentence(outputCorrect)
+	if defaultParser != nil {
+		t.Fatal("default parser initialized before first use")
+	}
+
+	// A file that exists but is not a model passes Validate and fails in
+	// cabocha_new2.
+	model := filepath.Join(t.TempDir(), "dep.unidic")
+	if err := os.WriteFile(model, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDefaultOptions(Options{ParserModel: model}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DefaultParser(); !errors.Is(err, ErrModelLoad) {
+		t.Errorf("expected %v got %v", ErrModelLoad, err)
+	}
+	if err := SetDefaultOptions(Options{}); err != nil {
+		t.Errorf("expected options to be settable after a failed init, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+// #cgo LDFLAGS: -lcabocha
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <cabocha.h>
+// struct cabocha_t {};
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Parser wraps a CaboCha instance. The underlying C handle is released by
+// Close, or by a finalizer if the Parser becomes unreachable first.
+// CaboCha itself is not thread-safe, so calls on one Parser are serialized;
+// use a ParserPool to parse in parallel.
+type Parser struct {
+	mu      sync.Mutex
+	cabocha *C.cabocha_t
+	decode  DecodeOptions
+}
+
+// Returns a new Parser initialized with the given CaboCha command-line
+// option string. Failures reported by CaboCha are returned as an *Error
+// wrapping ErrInvalidOption or ErrModelLoad.
+func NewParser(opt string) (*Parser, error) {
+	cOpt := C.CString(opt)
+	defer C.free(unsafe.Pointer(cOpt))
+	cabocha := C.cabocha_new2(cOpt)
+	if cabocha == nil {
+		// With a NULL handle cabocha_strerror returns the global error.
+		return nil, newError(C.GoString(C.cabocha_strerror(nil)))
+	}
+	p := &Parser{cabocha: cabocha}
+	runtime.SetFinalizer(p, (*Parser).Close)
+	return p, nil
+}
+
+// Close destroys the underlying CaboCha instance. It is safe to call Close
+// more than once.
+func (p *Parser) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cabocha == nil {
+		return
+	}
+	C.cabocha_destroy(p.cabocha)
+	p.cabocha = nil
+	runtime.SetFinalizer(p, nil)
+}
+
+// ParseToFormat parses s and returns the CaboCha output in the given
+// format (one of the Format* constants).
+func (p *Parser) ParseToFormat(s string, format int) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tree, err := p.sparseToTree(s)
+	if err != nil {
+		return "", err
+	}
+	out := C.cabocha_tree_tostr(tree, C.int(format))
+	if out == nil {
+		return "", p.parseError()
+	}
+	return C.GoString(out), nil
+}
+
+// Parse parses text and returns it as a Sentence built directly from the
+// CaboCha tree. The context is checked before CaboCha is called; a parse in
+// progress cannot be interrupted.
+func (p *Parser) Parse(ctx context.Context, text string) (*Sentence, error) {
+	return p.parseAt(ctx, text, position{})
+}
+
+// Parses text, which starts at base in a larger document.
+func (p *Parser) parseAt(ctx context.Context, text string, base position) (*Sentence, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tree, err := p.sparseToTree(text)
+	if err != nil {
+		return nil, err
+	}
+	s := sentenceFromTree(tree)
+	s.applySchema(p.decode)
+	if p.decode.Schema == nil {
+		p.decode.Schema = s.detectSchema()
+	}
+	s.align(text, base, p.decode.UTF16)
+	return s, nil
+}
+
+// The tree returned by cabocha_sparse_totree is owned by the CaboCha
+// instance and is reused by the next call, so it is not freed here and must
+// be consumed while p.mu is held.
+func (p *Parser) sparseToTree(s string) (*C.cabocha_tree_t, error) {
+	if p.cabocha == nil {
+		return nil, ErrClosed
+	}
+	if strings.IndexByte(s, 0) != -1 {
+		return nil, ErrInvalidInput
+	}
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	tree := C.cabocha_sparse_totree(p.cabocha, cs)
+	if tree == nil {
+		return nil, p.parseError()
+	}
+	return tree, nil
+}
+
+// Rounds a chunk score the way the lattice output prints it ("%f"), so
+// sentences built from trees and from text compare equal.
+func roundScore(score C.float) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(score), 'f', 6, 64), 64)
+	return f
+}
+
+// Builds a Sentence from the chunk and token accessors of a CaboCha tree.
+func sentenceFromTree(tree *C.cabocha_tree_t) *Sentence {
+	s := new(Sentence)
+	chunkSize := int(C.cabocha_tree_chunk_size(tree))
+	for ci := 0; ci < chunkSize; ci++ {
+		chunk := C.cabocha_tree_chunk(tree, C.size_t(ci))
+		c := &Chunk{
+			Id:   int64(ci),
+			Link: int64(chunk.link),
+			Prob: roundScore(chunk.score),
+			Head: int64(chunk.head_pos),
+			Tail: int64(chunk.func_pos),
+		}
+		for ti := chunk.token_pos; ti < chunk.token_pos+chunk.token_size; ti++ {
+			token := C.cabocha_tree_token(tree, ti)
+			features := make([]string, int(token.feature_list_size))
+			if len(features) > 0 {
+				list := unsafe.Slice(token.feature_list, len(features))
+				for j := range features {
+					features[j] = C.GoString(list[j])
+				}
+			}
+			ne := ""
+			if token.ne != nil {
+				ne = C.GoString(token.ne)
+			}
+			c.Tokens = append(c.Tokens, newToken(C.GoString(token.surface), features, ne))
+		}
+		s.Chunks = append(s.Chunks, c)
+	}
+	s.Resolve()
+	return s
+}
+
+func (p *Parser) parseError() error {
+	return &Error{Op: "parse", Msg: C.GoString(C.cabocha_strerror(p.cabocha)), Err: ErrParse}
+}
+
+var (
+	defaultMu      sync.Mutex
+	defaultOptions Options
+	defaultParser  *Parser
+)
+
+// SetDefaultOptions configures the package-level default parser used by
+// Parse, ParseString and the other convenience functions. It must be called
+// before the default parser is created and returns ErrDefaultInitialized
+// afterwards.
+func SetDefaultOptions(o Options) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultParser != nil {
+		return ErrDefaultInitialized
+	}
+	defaultOptions = o
+	return nil
+}
+
+// DefaultParser returns the package-level default parser, creating it on
+// first use. Packages that only decode saved CaboCha output never call into
+// libcabocha. If creation fails, the error is returned and the next call
+// tries again, so the options can still be corrected with
+// SetDefaultOptions.
+func DefaultParser() (*Parser, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultParser == nil {
+		p, err := NewParserWithOptions(defaultOptions)
+		if err != nil {
+			return nil, err
+		}
+		defaultParser = p
+	}
+	return defaultParser, nil
+}
+
+// Parse parses text with the package-level default parser.
+func Parse(ctx context.Context, text string) (*Sentence, error) {
+	p, err := DefaultParser()
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse(ctx, text)
+}
+
+// ParseString parses text with the package-level default parser and
+// returns the CaboCha output in the given format.
+func ParseString(ctx context.Context, text string, format int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	p, err := DefaultParser()
+	if err != nil {
+		return "", err
+	}
+	return p.ParseToFormat(text, format)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"context"
+	"sync"
+)
+
+// ParserPool owns a fixed number of independent Parsers created from the
+// same options. CaboCha instances are not thread-safe, so each call borrows
+// one Parser exclusively; when all are in use callers block until one is
+// returned or their context is done. The pool is safe for concurrent use.
+type ParserPool struct {
+	parsers chan *Parser
+	size    int
+	once    sync.Once
+	done    chan struct{}
+}
+
+// Returns a new ParserPool of n Parsers created with the given CaboCha
+// option string. If any Parser fails to initialize, the ones already
+// created are closed and the error is returned.
+func NewParserPool(n int, opt string) (*ParserPool, error) {
+	return newParserPool(n, func() (*Parser, error) {
+		return NewParser(opt)
+	})
+}
+
+func newParserPool(n int, newParser func() (*Parser, error)) (*ParserPool, error) {
+	if n < 1 {
+		n = 1
+	}
+	pool := &ParserPool{
+		parsers: make(chan *Parser, n),
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < n; i++ {
+		p, err := newParser()
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.size++
+		pool.parsers <- p
+	}
+	return pool, nil
+}
+
+// Get borrows a Parser from the pool, blocking until one is free. The
+// Parser must be handed back with Put.
+func (pool *ParserPool) Get(ctx context.Context) (*Parser, error) {
+	select {
+	case <-pool.done:
+		return nil, ErrClosed
+	default:
+	}
+	select {
+	case p := <-pool.parsers:
+		return p, nil
+	case <-pool.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Put returns a Parser obtained from Get to the pool.
+func (pool *ParserPool) Put(p *Parser) {
+	pool.parsers <- p
+}
+
+// Parse parses text with a Parser borrowed from the pool.
+func (pool *ParserPool) Parse(ctx context.Context, text string) (*Sentence, error) {
+	p, err := pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Put(p)
+	return p.Parse(ctx, text)
+}
+
+// ParseToFormat parses text with a Parser borrowed from the pool and
+// returns the CaboCha output in the given format.
+func (pool *ParserPool) ParseToFormat(ctx context.Context, text string, format int) (string, error) {
+	p, err := pool.Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer pool.Put(p)
+	return p.ParseToFormat(text, format)
+}
+
+// Close stops the pool from handing out Parsers, waits for borrowed ones
+// to be returned and destroys them all.
+func (pool *ParserPool) Close() {
+	pool.once.Do(func() {
+		close(pool.done)
+		for i := 0; i < pool.size; i++ {
+			(<-pool.parsers).Close()
+		}
+	})
+}
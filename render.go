@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT returns s as a Graphviz digraph: one node per chunk listing its
+// tokens (the head token in bold), and an edge from every chunk to the
+// chunk it depends on, labelled with the score.
+func (s *Sentence) ToDOT() string {
+	var b strings.Builder
+	b.WriteString("digraph sentence {\n")
+	b.WriteString("  node [shape=plaintext];\n")
+	for _, c := range s.Chunks {
+		fmt.Fprintf(&b, "  c%d [label=<<table border=\"%d\" cellborder=\"1\" cellspacing=\"0\"><tr><td colspan=\"%d\">%d</td></tr><tr>",
+			c.Id, rootBorder(c), max(len(c.Tokens), 1), c.Id)
+		for i, t := range c.Tokens {
+			if i == int(c.Head) {
+				fmt.Fprintf(&b, "<td><b>%s</b></td>", dotEscaper.Replace(t.Surface()))
+			} else {
+				fmt.Fprintf(&b, "<td>%s</td>", dotEscaper.Replace(t.Surface()))
+			}
+		}
+		if len(c.Tokens) == 0 {
+			b.WriteString("<td></td>")
+		}
+		b.WriteString("</tr></table>>];\n")
+	}
+	for _, c := range s.Chunks {
+		if p := c.Parent(); p != nil {
+			fmt.Fprintf(&b, "  c%d -> c%d [label=\"%f\"];\n", c.Id, p.Id, c.Prob)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var dotEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// The root chunk is drawn with a border around its table.
+func rootBorder(c *Chunk) int {
+	if c.Link == -1 {
+		return 1
+	}
+	return 0
+}
+
+// ToASCIITree returns s drawn the way "cabocha -f0" does: one chunk per
+// line, right-aligned so that a chunk's dependency arrow ("-D") ends
+// below the last character of the chunk it depends on, with "|" carrying
+// arrows past the chunks in between. Wide characters count as two
+// columns.
+func (s *Sentence) ToASCIITree() string {
+	n := len(s.Chunks)
+	surfaces := make([]string, n)
+	widths := make([]int, n)
+	base := 0
+	for i, c := range s.Chunks {
+		for _, t := range c.Tokens {
+			surfaces[i] += t.Surface()
+		}
+		widths[i] = displayWidth(surfaces[i])
+		base = max(base, widths[i]-2*i)
+	}
+
+	var b strings.Builder
+	arrived := make([]bool, n) // an arrow ends at chunk j
+	for i, c := range s.Chunks {
+		b.WriteString(strings.Repeat(" ", base+2*i-widths[i]))
+		b.WriteString(surfaces[i])
+		// Links that do not point forward are not drawn.
+		linked := c.Link <= int64(i) || c.Link >= int64(n)
+		for j := i + 1; j < n; j++ {
+			switch {
+			case int64(j) == c.Link:
+				b.WriteString("-D")
+				linked = true
+				arrived[j] = true
+			case arrived[j]:
+				if linked {
+					b.WriteString(" |")
+				} else {
+					b.WriteString("-|")
+				}
+			case linked:
+				b.WriteString("  ")
+			default:
+				b.WriteString("--")
+			}
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("EOS\n")
+	return b.String()
+}
+
+// Returns the number of terminal columns s takes up, counting ASCII and
+// half-width katakana as one column and everything else as two.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		if r < 0x80 || r >= 0xFF61 && r <= 0xFF9F {
+			w++
+		} else {
+			w += 2
+		}
+	}
+	return w
+}
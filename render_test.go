@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+var latticeTreeASCII = `太郎は---------D
+  花子が-D     |
+読んでいる-D   |
+        本を---D
+        次郎に-D
+          渡した
+EOS
+`
+
+func TestToASCIITree(t *testing.T) {
+	for lattice, expected := range map[string]string{
+		latticeTree:   latticeTreeASCII,
+		outputCorrect: "hello，未知語\nEOS\n",
+		"EOS\n":       "EOS\n",
+	} {
+		if output := NewSentence(lattice).ToASCIITree(); output != expected {
+			t.Errorf("Echo: expected\n%s\ngot\n%s", expected, output)
+		}
+	}
+}
+
+func TestToASCIITreeCrossing(t *testing.T) {
+	s := NewSentence(latticeTree)
+	s.Chunks[2].Link = 4 // crosses 3 -> 5
+	s.Resolve()
+	lines := strings.Split(s.ToASCIITree(), "\n")
+	if expected := "        本を-|-D"; lines[3] != expected {
+		t.Errorf("expected %q got %q", expected, lines[3])
+	}
+}
+
+func TestToDOT(t *testing.T) {
+	s := NewSentence(latticeTree)
+	s.Chunks[3].Tokens[0].surface = "<本>"
+	output := s.ToDOT()
+	for _, expected := range []string{
+		"digraph sentence {\n",
+		"  c2 [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\"><tr><td colspan=\"3\">2</td></tr><tr><td><b>読ん</b></td><td>で</td><td>いる</td></tr></table>>];\n",
+		"<td><b>&lt;本&gt;</b></td>",
+		"  c5 [label=<<table border=\"1\"",
+		"  c0 -> c5 [label=\"0.000000\"];\n",
+		"  c1 -> c2 [label=\"0.000000\"];\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in\n%s", expected, output)
+		}
+	}
+	if strings.Count(output, "->") != 5 {
+		t.Errorf("expected 5 edges in\n%s", output)
+	}
+}
+
+func TestParseToASCIITree(t *testing.T) {
+	p, err := NewParser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	expected, err := p.ParseToFormat(input, FormatTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := p.Parse(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := s.ToASCIITree(); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+}
@@ -0,0 +1,474 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FeatureSchema maps the feature columns of a MeCab dictionary onto Token
+// fields.
+type FeatureSchema interface {
+	// Name identifies the schema, e.g. "unidic-2.1".
+	Name() string
+	// Columns names the feature columns of a known word, in order.
+	Columns() []string
+	// Apply sets the fields of t from features, which are shorter than
+	// Columns for unknown words.
+	Apply(t *Token, features []string)
+	// Features rebuilds the feature list of t from its fields.
+	Features(t *Token) []string
+}
+
+// Built-in schemas. UniDic 2.3 and 3.x share a column layout, so
+// DetectSchema reports UniDic3 for both.
+var (
+	UniDic1 FeatureSchema = &columnSchema{
+		name: "unidic-1",
+		columns: []string{
+			"pos1", "pos2", "pos3", "pos4", "cType", "cForm", "lForm", "lemma",
+			"orth", "pron", "orthBase", "pronBase", "goshu", "iType", "iForm",
+			"fType", "fForm",
+		},
+		required: 17,
+		unknown:  6,
+	}
+	UniDic21 FeatureSchema = &columnSchema{
+		name:     "unidic-2.1",
+		columns:  append(append([]string(nil), uniDic2Columns...), "lid", "lemmaId"),
+		required: len(uniDic2Columns),
+		unknown:  6,
+	}
+	UniDic23 FeatureSchema = &columnSchema{
+		name:     "unidic-2.3",
+		columns:  uniDic3Columns,
+		required: 27,
+		unknown:  6,
+	}
+	UniDic3 FeatureSchema = &columnSchema{
+		name:     "unidic-3",
+		columns:  uniDic3Columns,
+		required: 27,
+		unknown:  6,
+	}
+	IPADIC FeatureSchema = &columnSchema{
+		name: "ipadic",
+		columns: []string{
+			"pos1", "pos2", "pos3", "pos4", "cType", "cForm", "baseForm",
+			"reading", "pronunciation",
+		},
+		targets: map[string][]string{
+			"baseForm":      {"lemma", "orthBase"},
+			"reading":       {"kana"},
+			"pronunciation": {"pron"},
+		},
+		required: 9,
+		unknown:  7,
+	}
+	JUMAN FeatureSchema = &columnSchema{
+		name:    "juman",
+		columns: []string{"pos1", "pos2", "cType", "cForm", "baseForm", "reading", "info"},
+		targets: map[string][]string{
+			"baseForm": {"lemma", "orthBase"},
+			"reading":  {"kana"},
+		},
+		required: 7,
+		unknown:  7,
+		// JUMAN unknown words keep all seven columns, with no base
+		// form or reading.
+		blankUnknown: []int{4, 5},
+		posColumns:   4,
+	}
+	// CoNLLU holds what a CoNLLUDecoder reads: the part of speech and
+	// conjugation from XPOS and MISC, and the lemma. DetectSchema does
+	// not report it, so lattice output written with it must be decoded
+	// with DecodeOptions.Schema set.
+	CoNLLU FeatureSchema = &columnSchema{
+		name:    "conllu",
+		columns: []string{"pos1", "pos2", "pos3", "pos4", "cType", "cForm", "lemma"},
+		targets: map[string][]string{
+			"lemma": {"lemma", "orthBase"},
+		},
+		required: 7,
+		unknown:  6,
+	}
+)
+
+// Column names of the UniDic 2 feature layout used by CaboCha's bundled
+// models.
+var uniDic2Columns = []string{
+	"pos1", "pos2", "pos3", "pos4", "cType", "cForm", "lForm", "lemma",
+	"orth", "pron", "kana", "goshu", "orthBase", "pronBase", "kanaBase",
+	"formBase", "iType", "iForm", "iConType", "fType", "fForm", "fConType",
+	"aType", "aConType", "aModType",
+}
+
+// Column names of the UniDic 2.3 and 3.x feature layout.
+var uniDic3Columns = []string{
+	"pos1", "pos2", "pos3", "pos4", "cType", "cForm", "lForm", "lemma",
+	"orth", "pron", "orthBase", "pronBase", "goshu", "iType", "iForm",
+	"fType", "fForm", "iConType", "fConType", "type", "kana", "kanaBase",
+	"form", "formBase", "aType", "aConType", "aModType", "lid", "lemmaId",
+}
+
+var (
+	schemasMu sync.RWMutex
+	schemas   = []FeatureSchema{UniDic1, UniDic21, UniDic23, UniDic3, IPADIC, JUMAN, CoNLLU}
+)
+
+// RegisterSchema makes a custom schema known to LookupSchema, so that
+// sentences decoded with it keep it when read back from JSON. It returns
+// an error if a schema of the same name is already registered.
+func RegisterSchema(schema FeatureSchema) error {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	for _, s := range schemas {
+		if s.Name() == schema.Name() {
+			return fmt.Errorf("cabocha: schema %q already registered", schema.Name())
+		}
+	}
+	schemas = append(schemas, schema)
+	return nil
+}
+
+// LookupSchema returns the built-in or registered schema with the given
+// name, or nil.
+func LookupSchema(name string) FeatureSchema {
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+	for _, schema := range schemas {
+		if schema.Name() == name {
+			return schema
+		}
+	}
+	return nil
+}
+
+// Stands in for a schema that is not registered, as when reading JSON
+// written with a custom schema. It names no columns, so the raw features
+// are kept as they are and the named fields as they were written.
+type unknownSchema string
+
+func (us unknownSchema) Name() string {
+	return string(us)
+}
+
+func (us unknownSchema) Columns() []string {
+	return nil
+}
+
+func (us unknownSchema) Apply(t *Token, features []string) {}
+
+func (us unknownSchema) Features(t *Token) []string {
+	return t.Features
+}
+
+// DetectSchema guesses the schema from the feature list of one token. It
+// returns nil if the list does not tell, as for unknown words. UniDic
+// layouts are told apart by where the goshu (word origin) column falls,
+// since lid and lemmaId are optional and lists longer than a known layout
+// are assumed to carry extra user dictionary columns.
+func DetectSchema(features []string) FeatureSchema {
+	n := len(features)
+	switch {
+	case n >= 25 && goshu[features[11]]:
+		return UniDic21
+	case n >= 27 && goshu[features[12]]:
+		return UniDic3
+	case n >= 17 && goshu[features[12]]:
+		return UniDic1
+	case n >= 9:
+		return IPADIC
+	case n >= 7 && features[4] != "*" && features[5] != "*":
+		// A known JUMAN word always has a base form and reading. IPADIC
+		// unknown words also have seven columns, but those are "*", as
+		// they are for JUMAN unknown words, so such lists do not tell.
+		return JUMAN
+	}
+	return nil
+}
+
+// Values of the UniDic goshu column.
+var goshu = map[string]bool{
+	"和": true, "漢": true, "外": true, "混": true, "固": true,
+	"記号": true, "他": true, "不明": true,
+}
+
+// A FeatureSchema backed by a list of column names. Columns are mapped to
+// the Token field with the same JSON name unless listed in targets.
+type columnSchema struct {
+	name     string
+	columns  []string
+	targets  map[string][]string
+	required int // columns of a known word
+	unknown  int // columns of an unknown word
+
+	// Columns that are "*" only for unknown words, for dictionaries
+	// whose unknown words are not shorter than known ones.
+	blankUnknown []int
+	// Leading columns that unknown words have values for; 6 if zero.
+	posColumns int
+}
+
+func (cs *columnSchema) Name() string {
+	return cs.name
+}
+
+func (cs *columnSchema) Columns() []string {
+	return cs.columns
+}
+
+func (cs *columnSchema) Apply(t *Token, features []string) {
+	unknown := cs.isUnknown(features)
+	for i, column := range cs.columns {
+		if i >= len(features) || unknown && i >= cs.known() {
+			break
+		}
+		for _, target := range cs.target(column) {
+			if field := t.field(target); field != nil {
+				*field = features[i]
+			}
+		}
+	}
+	if !cs.has("orth") {
+		t.Orth = t.Surface()
+	}
+	if unknown {
+		t.Unknown = true
+		t.Orth = t.Surface()
+	}
+}
+
+// Reports whether features are those of an unknown word.
+func (cs *columnSchema) isUnknown(features []string) bool {
+	if len(features) < cs.required {
+		return true
+	}
+	if len(cs.blankUnknown) == 0 {
+		return false
+	}
+	for _, i := range cs.blankUnknown {
+		if features[i] != "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the number of leading columns unknown words have values for.
+func (cs *columnSchema) known() int {
+	if cs.posColumns == 0 {
+		return 6
+	}
+	return cs.posColumns
+}
+
+func (cs *columnSchema) Features(t *Token) []string {
+	n := len(cs.columns)
+	if t.Unknown {
+		n = cs.unknown
+	} else if n > cs.required && t.LID == "" && t.LemmaID == "" {
+		n = cs.required // optional lemma ID columns
+	}
+	features := make([]string, n)
+	for i := range features {
+		// Only the part of speech is known for unknown words.
+		if field := t.field(cs.target(cs.columns[i])[0]); field != nil && !(t.Unknown && i >= cs.known()) {
+			features[i] = *field
+		} else {
+			features[i] = "*"
+		}
+	}
+	return features
+}
+
+func (cs *columnSchema) target(column string) []string {
+	if targets, ok := cs.targets[column]; ok {
+		return targets
+	}
+	return []string{column}
+}
+
+func (cs *columnSchema) has(field string) bool {
+	for _, column := range cs.columns {
+		for _, target := range cs.target(column) {
+			if target == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns a pointer to the field of t with the given JSON name, or nil.
+func (t *Token) field(name string) *string {
+	switch name {
+	case "pos1":
+		return &t.Pos1
+	case "pos2":
+		return &t.Pos2
+	case "pos3":
+		return &t.Pos3
+	case "pos4":
+		return &t.Pos4
+	case "cType":
+		return &t.CType
+	case "cForm":
+		return &t.CForm
+	case "lForm":
+		return &t.LForm
+	case "lemma":
+		return &t.Lemma
+	case "orth":
+		return &t.Orth
+	case "pron":
+		return &t.Pron
+	case "kana":
+		return &t.Kana
+	case "goshu":
+		return &t.Goshu
+	case "orthBase":
+		return &t.OrthBase
+	case "pronBase":
+		return &t.PronBase
+	case "kanaBase":
+		return &t.KanaBase
+	case "formBase":
+		return &t.FormBase
+	case "iType":
+		return &t.IType
+	case "iForm":
+		return &t.IForm
+	case "iConType":
+		return &t.IConType
+	case "fType":
+		return &t.FType
+	case "fForm":
+		return &t.FForm
+	case "fConType":
+		return &t.FConType
+	case "aType":
+		return &t.AType
+	case "aConType":
+		return &t.AConType
+	case "aModType":
+		return &t.AModType
+	case "type":
+		return &t.Type
+	case "form":
+		return &t.Form
+	case "lid":
+		return &t.LID
+	case "lemmaId":
+		return &t.LemmaID
+	}
+	return nil
+}
+
+// Returns the schema detected from the first token of s that tells it
+// apart, or nil.
+func (s *Sentence) detectSchema() FeatureSchema {
+	for _, t := range s.Tokens() {
+		if schema := DetectSchema(t.Features); schema != nil {
+			return schema
+		}
+	}
+	return nil
+}
+
+// Sets the fields of every token in s from its feature list using
+// o.Schema, and fills in unknown words according to o.Unknown. If the
+// schema is nil it is detected from s, and UniDic21 is assumed when nothing
+// tells.
+func (s *Sentence) applySchema(o DecodeOptions) {
+	schema := o.Schema
+	if schema == nil {
+		schema = s.detectSchema()
+	}
+	if schema == nil {
+		schema = UniDic21
+	}
+	s.schema = schema
+	for _, t := range s.Tokens() {
+		t.schema = schema
+		schema.Apply(t, t.Features)
+		if t.Unknown {
+			o.Unknown.apply(t, schema)
+		}
+	}
+}
+
+// UnknownFallback selects what fills the fields of an unknown word that
+// the dictionary has no value for.
+type UnknownFallback int
+
+const (
+	// UnknownCopySurface copies the surface string into Lemma and
+	// OrthBase, and sets Goshu to "不明" if the schema has that column.
+	UnknownCopySurface UnknownFallback = iota
+	// UnknownEmpty leaves them empty.
+	UnknownEmpty
+)
+
+// UnknownPolicy controls how the fields of unknown words are filled in.
+// Their Orth is always the surface string, and Unknown is set so they can
+// be told apart from dictionary words whatever the policy.
+type UnknownPolicy struct {
+	Fallback UnknownFallback
+	// GuessReading fills Kana, KanaBase, Pron and PronBase with a
+	// katakana reading if the surface is written in kana or romaji.
+	GuessReading bool
+}
+
+func (p UnknownPolicy) apply(t *Token, schema FeatureSchema) {
+	if p.Fallback == UnknownCopySurface {
+		t.Lemma = t.Surface()
+		t.OrthBase = t.Surface()
+		for _, column := range schema.Columns() {
+			if column == "goshu" {
+				t.Goshu = "不明"
+			}
+		}
+	}
+	if p.GuessReading {
+		if reading, ok := guessReading(t.Surface()); ok {
+			t.Kana = reading
+			t.KanaBase = reading
+			t.Pron = reading
+			t.PronBase = reading
+		}
+	}
+}
+
+// Schema returns the feature schema the sentence was decoded with.
+func (s *Sentence) Schema() FeatureSchema {
+	return s.schema
+}
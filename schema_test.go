@@ -0,0 +1,242 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var latticeIPADIC = `* 0 1D 0/1 0.000000
+太郎	名詞,固有名詞,人名,名,*,*,太郎,タロウ,タロー	O
+は	助詞,係助詞,*,*,*,*,は,ハ,ワ	O
+* 1 -1D 0/0 0.000000
+ｈｏｇｅ	名詞,一般,*,*,*,*,*	O
+EOS
+`
+
+var latticeJUMAN = `* 0 -1D 0/1 0.000000
+日本	名詞,地名,*,*,日本,にほん,代表表記:日本/にほん 地名:国	O
+だ	判定詞,*,判定詞,ダ列基本形,だ,だ,*	O
+ｈｏｇｅ	未定義語,アルファベット,*,*,*,*,*	O
+EOS
+`
+
+var latticeUniDic3 = `* 0 -1D 0/0 0.000000
+語	名詞,普通名詞,一般,*,*,*,ゴ,語,語,ゴ,語,ゴ,漢,*,*,*,*,*,*,体,ゴ,ゴ,ゴ,ゴ,1,C3,*,3410,12390	O
+EOS
+`
+
+func TestDetectSchema(t *testing.T) {
+	tests := []struct {
+		lattice  string
+		expected FeatureSchema
+	}{
+		{outputCorrect, UniDic21},
+		{latticeIPADIC, IPADIC},
+		{latticeJUMAN, JUMAN},
+		{latticeUniDic3, UniDic3},
+	}
+	for _, test := range tests {
+		if output := NewSentence(test.lattice).Schema(); output != test.expected {
+			t.Errorf("Echo: expected %s got %v", test.expected.Name(), output)
+		}
+	}
+}
+
+func TestDetectSchemaColumns(t *testing.T) {
+	uniDic3 := "名詞,普通名詞,一般,*,*,*,ゴ,語,語,ゴ,語,ゴ,漢,*,*,*,*,*,*,体,ゴ,ゴ,ゴ,ゴ,1,C3,*"
+	uniDic21 := "名詞,普通名詞,一般,*,*,*,ゴ,語,語,ゴ,ゴ,漢,語,ゴ,ゴ,ゴ,*,*,*,*,*,*,1,C3,*"
+	ipadic := "名詞,固有名詞,人名,名,*,*,太郎,タロウ,タロー"
+	tests := []struct {
+		features string
+		expected FeatureSchema
+	}{
+		{uniDic3, UniDic3},
+		{uniDic3 + ",3410,12390", UniDic3},
+		{uniDic21, UniDic21},
+		{uniDic21 + ",3410,12390", UniDic21},
+		{"名詞,普通名詞,一般,*,*,*,ゴ,語,語,ゴ,語,ゴ,漢,*,*,*,*", UniDic1},
+		{ipadic, IPADIC},
+		{ipadic + strings.Repeat(",user", 8), IPADIC},
+		{ipadic + strings.Repeat(",user", 20), IPADIC},
+		{"名詞,一般,*,*,*,*", nil},
+		{"助詞,副助詞,*,*,は,は,*", JUMAN},
+		{"名詞,一般,*,*,*,*,*", nil},
+		{"特殊,記号,*,*,*,*,*", nil},
+	}
+	for _, test := range tests {
+		if output := DetectSchema(strings.Split(test.features, ",")); output != test.expected {
+			t.Errorf("DetectSchema(%q): expected %v got %v", test.features, test.expected, output)
+		}
+	}
+}
+
+func TestSchemaFields(t *testing.T) {
+	tokens := NewSentence(latticeIPADIC).Tokens()
+	if tok := tokens[0]; tok.Lemma != "太郎" || tok.OrthBase != "太郎" || tok.Kana != "タロウ" || tok.Pron != "タロー" || tok.Orth != "太郎" {
+		t.Errorf("IPADIC token decoded incorrectly: %+v", tok)
+	}
+	if tok := tokens[2]; !tok.Unknown || tok.Lemma != "ｈｏｇｅ" || tok.Goshu != "" {
+		t.Errorf("IPADIC unknown word decoded incorrectly: %+v", tok)
+	}
+
+	tokens = NewSentence(latticeJUMAN).Tokens()
+	tok := tokens[0]
+	if tok.Unknown || tok.Pos2 != "地名" || tok.CType != "*" || tok.Lemma != "日本" || tok.Kana != "にほん" {
+		t.Errorf("JUMAN token decoded incorrectly: %+v", tok)
+	}
+	if tok := tokens[2]; !tok.Unknown || tok.Lemma != "ｈｏｇｅ" || tok.Kana != "" {
+		t.Errorf("JUMAN unknown word decoded incorrectly: %+v", tok)
+	}
+
+	noLemmaIDs := strings.Replace(latticeUniDic3, ",3410,12390", "", 1)
+	tok = NewSentence(noLemmaIDs).Tokens()[0]
+	if tok.OrthBase != "語" || tok.Kana != "ゴ" || tok.Goshu != "漢" || tok.LID != "" {
+		t.Errorf("UniDic 3 token without lemma IDs decoded incorrectly: %+v", tok)
+	}
+
+	tok = NewSentence(latticeUniDic3).Tokens()[0]
+	if tok.Kana != "ゴ" || tok.Goshu != "漢" || tok.AType != "1" || tok.LID != "3410" || tok.LemmaID != "12390" ||
+		tok.Type != "体" || tok.Form != "ゴ" {
+		t.Errorf("UniDic 3 token decoded incorrectly: %+v", tok)
+	}
+}
+
+func TestSchemaRoundTrip(t *testing.T) {
+	for _, lattice := range []string{latticeIPADIC, latticeJUMAN} {
+		s := NewSentence(lattice)
+		if output := s.ToLattice(); output != lattice {
+			t.Errorf("Echo: expected %q got %q", lattice, output)
+		}
+	}
+	for _, lattice := range []string{latticeIPADIC, latticeUniDic3} {
+		s := NewSentence(lattice)
+		for _, tok := range s.Tokens() {
+			tok.Features = nil
+		}
+		if output := s.ToLattice(); output != lattice {
+			t.Errorf("Echo: expected %q got %q", lattice, output)
+		}
+	}
+}
+
+func TestDecoderDetectsJUMANFromStarInfo(t *testing.T) {
+	first := "* 0 -1D 0/0 0.000000\nは\t助詞,副助詞,*,*,は,は,*\tO\nEOS\n"
+	d := NewDecoder(strings.NewReader(first + latticeJUMAN))
+	var s *Sentence
+	for i := 0; i < 2; i++ {
+		var err error
+		if s, err = d.Next(); err != nil {
+			t.Fatal(err)
+		}
+		if s.Schema() != JUMAN {
+			t.Fatalf("sentence %d: expected %s got %s", i, JUMAN.Name(), s.Schema().Name())
+		}
+	}
+	if tok := s.Tokens()[1]; tok.Unknown || tok.Lemma != "だ" || tok.Kana != "だ" {
+		t.Errorf("JUMAN token decoded incorrectly: %+v", tok)
+	}
+}
+
+func TestDecoderSchemaOption(t *testing.T) {
+	unknownOnly := "* 0 -1D 0/0 0.000000\nｈｏｇｅ\t名詞,一般,*,*,*,*\tO\nEOS\n"
+
+	d := NewDecoder(strings.NewReader(latticeIPADIC + unknownOnly))
+	for i := 0; i < 2; i++ {
+		s, err := d.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Schema() != IPADIC {
+			t.Errorf("sentence %d: expected %s got %s", i, IPADIC.Name(), s.Schema().Name())
+		}
+	}
+
+	d = NewDecoderWithOptions(strings.NewReader(latticeIPADIC), DecodeOptions{Schema: JUMAN})
+	s, err := d.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Schema() != JUMAN {
+		t.Errorf("expected %s got %s", JUMAN.Name(), s.Schema().Name())
+	}
+
+	if LookupSchema("juman") != JUMAN || LookupSchema("nope") != nil {
+		t.Errorf("LookupSchema returned the wrong schema")
+	}
+}
+
+func TestTokenFeature(t *testing.T) {
+	lattice := "* 0 -1D 0/0 0.000000\n太郎\t名詞,固有名詞,人名,名,*,*,太郎,タロウ,タロー,人物辞書\tO\nEOS\n"
+	s := NewSentence(lattice)
+	if s.Schema() != IPADIC {
+		t.Fatalf("expected %s got %s", IPADIC.Name(), s.Schema().Name())
+	}
+	tok := s.Tokens()[0]
+	if v, ok := tok.Feature("reading"); !ok || v != "タロウ" {
+		t.Errorf("Feature(reading): expected %q got %q", "タロウ", v)
+	}
+	if extra := tok.Extra(); len(extra) != 1 || extra[0] != "人物辞書" {
+		t.Errorf("Extra: expected [人物辞書] got %q", extra)
+	}
+	if _, ok := tok.Feature("aType"); ok {
+		t.Errorf("Feature(aType): expected no such column in %s", IPADIC.Name())
+	}
+	if output := s.ToLattice(); output != lattice {
+		t.Errorf("Echo: expected %q got %q", lattice, output)
+	}
+}
+
+func TestUnknownPolicy(t *testing.T) {
+	lattice := "* 0 -1D 0/0 0.000000\nsushi\t名詞,普通名詞,一般,*,*,*\tO\nEOS\n"
+	tests := []struct {
+		policy   UnknownPolicy
+		expected Token
+	}{
+		{UnknownPolicy{}, Token{Orth: "sushi", Lemma: "sushi", OrthBase: "sushi", Goshu: "不明"}},
+		{UnknownPolicy{Fallback: UnknownEmpty}, Token{Orth: "sushi"}},
+		{UnknownPolicy{Fallback: UnknownEmpty, GuessReading: true}, Token{Orth: "sushi", Kana: "スシ", KanaBase: "スシ", Pron: "スシ", PronBase: "スシ"}},
+	}
+	for _, test := range tests {
+		d := NewDecoderWithOptions(strings.NewReader(lattice), DecodeOptions{Unknown: test.policy})
+		s, err := d.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok := s.Tokens()[0]
+		output := Token{Orth: tok.Orth, Lemma: tok.Lemma, OrthBase: tok.OrthBase, Goshu: tok.Goshu,
+			Kana: tok.Kana, KanaBase: tok.KanaBase, Pron: tok.Pron, PronBase: tok.PronBase}
+		if !tok.Unknown || !reflect.DeepEqual(output, test.expected) {
+			t.Errorf("%+v: expected %+v got %+v", test.policy, test.expected, output)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SVGOptions controls the rendering of Sentence.ToSVG. The zero value
+// draws everything at a 16px font size.
+type SVGOptions struct {
+	FontSize     float64           // in pixels; 16 if zero
+	HideScores   bool              // leave the arcs unlabelled
+	HideFurigana bool              // leave out the readings above kanji
+	NEColors     map[string]string // fill colour by NE type; DefaultNEColors if nil
+}
+
+// DefaultNEColors are the highlight colours of the IREX named entity
+// types. Other types are highlighted in grey.
+var DefaultNEColors = map[string]string{
+	"ARTIFACT":     "#f3e5ff",
+	"DATE":         "#fff4cc",
+	"LOCATION":     "#dff3e3",
+	"MONEY":        "#e3f6f6",
+	"ORGANIZATION": "#e0ebff",
+	"PERCENT":      "#e3f6f6",
+	"PERSON":       "#fde2e2",
+	"TIME":         "#fff4cc",
+}
+
+// The horizontal extent of a token or chunk.
+type svgBox struct {
+	x, w float64
+}
+
+func (b svgBox) center() float64 {
+	return b.x + b.w/2
+}
+
+// ToSVG returns a standalone SVG image of s: the chunks as boxes in
+// sentence order, an arc above the text from every chunk to the chunk it
+// depends on (labelled with the score), named entities highlighted by
+// type and readings (Token.Pron) shown as furigana above words containing
+// kanji. The image uses no scripts or external resources.
+func (s *Sentence) ToSVG(opts SVGOptions) string {
+	size := opts.FontSize
+	if size <= 0 {
+		size = 16
+	}
+	colors := opts.NEColors
+	if colors == nil {
+		colors = DefaultNEColors
+	}
+	ruby, pad, gap := size/2, size/4, size
+
+	// Lay out tokens left to right, each as wide as its surface or its
+	// furigana, whichever is wider.
+	furigana := map[*Token]string{}
+	tokenBoxes := map[*Token]svgBox{}
+	chunkBoxes := make([]svgBox, len(s.Chunks))
+	x := gap / 2
+	for i, c := range s.Chunks {
+		start := x
+		x += pad
+		for _, t := range c.Tokens {
+			w := float64(displayWidth(t.Surface())) * size / 2
+			if reading := t.Pron; !opts.HideFurigana && reading != "" && reading != "*" && hasKanji(t.Surface()) {
+				furigana[t] = reading
+				w = max(w, float64(displayWidth(reading))*ruby/2)
+			}
+			tokenBoxes[t] = svgBox{x, w}
+			x += w
+		}
+		x += pad
+		chunkBoxes[i] = svgBox{start, x - start}
+		x += gap
+	}
+	width := x - gap/2
+
+	levels, maxLevel := arcLevels(s)
+	levelHeight := size
+	top := float64(maxLevel)*levelHeight + size
+	rubyRow := 0.0
+	if len(furigana) > 0 {
+		rubyRow = ruby + 2
+	}
+	boxHeight := pad + rubyRow + size*1.25 + pad
+	height := top + boxHeight + pad
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="sans-serif" font-size="%s">`+"\n",
+		svgNum(width), svgNum(height), svgNum(width), svgNum(height), svgNum(size))
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#555"/></marker></defs>` + "\n")
+
+	for i, c := range s.Chunks {
+		box := chunkBoxes[i]
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="3" fill="none" stroke="#888"/>`+"\n",
+			svgNum(box.x), svgNum(top), svgNum(box.w), svgNum(boxHeight))
+		for _, t := range c.Tokens {
+			tb := tokenBoxes[t]
+			if kind := neType(t.Ne); kind != "" {
+				color, ok := colors[kind]
+				if !ok {
+					color = "#eeeeee"
+				}
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"><title>%s</title></rect>`+"\n",
+					svgNum(tb.x), svgNum(top+pad), svgNum(tb.w), svgNum(boxHeight-2*pad), xmlEscaper.Replace(color), xmlEscaper.Replace(kind))
+			}
+			if reading, ok := furigana[t]; ok {
+				fmt.Fprintf(&b, `<text x="%s" y="%s" font-size="%s" text-anchor="middle" fill="#555">%s</text>`+"\n",
+					svgNum(tb.center()), svgNum(top+pad+ruby), svgNum(ruby), xmlEscaper.Replace(reading))
+			}
+			fmt.Fprintf(&b, `<text x="%s" y="%s" text-anchor="middle">%s</text>`+"\n",
+				svgNum(tb.center()), svgNum(top+pad+rubyRow+size), xmlEscaper.Replace(t.Surface()))
+		}
+	}
+
+	// A cubic Bézier whose control points are 4/3 as high as the arc
+	// peaks at the arc's height.
+	for i, c := range s.Chunks {
+		p := c.Parent()
+		if p == nil {
+			continue
+		}
+		// Arcs leave right of a chunk's centre and arrive left of it, so
+		// that outgoing and incoming arcs do not meet.
+		from, to := chunkBoxes[i].center()+size/4, chunkBoxes[chunkIndex(s, p)].center()-size/4
+		h := float64(levels[i]) * levelHeight
+		fmt.Fprintf(&b, `<path d="M%s,%s C%s,%s %s,%s %s,%s" fill="none" stroke="#555" marker-end="url(#arrow)"/>`+"\n",
+			svgNum(from), svgNum(top), svgNum(from), svgNum(top-h*4/3), svgNum(to), svgNum(top-h*4/3), svgNum(to), svgNum(top))
+		if !opts.HideScores {
+			fmt.Fprintf(&b, `<text x="%s" y="%s" font-size="%s" text-anchor="middle" fill="#555">%s</text>`+"\n",
+				svgNum((from+to)/2), svgNum(top-h-2), svgNum(size*0.6), strconv.FormatFloat(c.Prob, 'f', 3, 64))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// Assigns every arc a level one above the highest arc it spans, so that
+// nested arcs do not overlap. Chunks without a parent get level 0.
+func arcLevels(s *Sentence) ([]int, int) {
+	type arc struct{ chunk, lo, hi int }
+	var arcs []arc
+	for i, c := range s.Chunks {
+		if p := c.Parent(); p != nil {
+			j := chunkIndex(s, p)
+			lo, hi := min(i, j), max(i, j)
+			arcs = append(arcs, arc{i, lo, hi})
+		}
+	}
+	sort.SliceStable(arcs, func(a, b int) bool {
+		return arcs[a].hi-arcs[a].lo < arcs[b].hi-arcs[b].lo
+	})
+	levels := make([]int, len(s.Chunks))
+	maxLevel := 0
+	for n, a := range arcs {
+		level := 1
+		for _, inner := range arcs[:n] {
+			if inner.lo >= a.lo && inner.hi <= a.hi {
+				level = max(level, levels[inner.chunk]+1)
+			}
+		}
+		levels[a.chunk] = level
+		maxLevel = max(maxLevel, level)
+	}
+	return levels, maxLevel
+}
+
+// Returns the position of c in s.Chunks.
+func chunkIndex(s *Sentence, c *Chunk) int {
+	for i, other := range s.Chunks {
+		if other == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the type of an IOB2 tag such as "B-PERSON", or "" for "O".
+func neType(tag string) string {
+	if _, kind, ok := strings.Cut(tag, "-"); ok {
+		return kind
+	}
+	return ""
+}
+
+func hasKanji(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Formats a coordinate to at most two decimal places.
+func svgNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToSVG(t *testing.T) {
+	s := NewSentence(outputCorrect)
+	s.Chunks[0].Tokens[0].Ne = "B-ARTIFACT"
+	s.Chunks[0].Tokens[0].surface = "<hello>"
+	output := s.ToSVG(SVGOptions{})
+
+	// The output must be well-formed XML.
+	d := xml.NewDecoder(strings.NewReader(output))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("malformed SVG: %v\n%s", err, output)
+		}
+	}
+
+	for _, expected := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		`fill="#f3e5ff"><title>ARTIFACT</title>`,
+		`>&lt;hello&gt;</text>`,
+		`fill="#555">ミチ</text>`, // furigana over 未知
+		`>未知</text>`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in\n%s", expected, output)
+		}
+	}
+	if !strings.Contains(output, ">ゴ</text>") {
+		t.Errorf("expected furigana over 語")
+	}
+	if strings.Contains(output, "<script") || strings.Contains(output, "href") {
+		t.Errorf("SVG must be self-contained")
+	}
+}
+
+func TestToSVGArcs(t *testing.T) {
+	s := NewSentence(latticeTree)
+	s.Chunks[1].Prob = 1.287682
+	output := s.ToSVG(SVGOptions{})
+	if n := strings.Count(output, `marker-end="url(#arrow)"`); n != 5 {
+		t.Errorf("expected 5 arcs, got %d", n)
+	}
+	if !strings.Contains(output, ">1.288</text>") {
+		t.Errorf("expected score label in\n%s", output)
+	}
+
+	levels, maxLevel := arcLevels(s)
+	if expected := []int{3, 1, 1, 2, 1, 0}; !reflect.DeepEqual(levels, expected) || maxLevel != 3 {
+		t.Errorf("expected levels %v, got %v (max %d)", expected, levels, maxLevel)
+	}
+
+	output = s.ToSVG(SVGOptions{HideScores: true, HideFurigana: true, FontSize: 20})
+	if strings.Contains(output, ">1.288</text>") || !strings.Contains(output, `font-size="20"`) {
+		t.Errorf("options not applied in\n%s", output)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Returns the katakana reading of s if it is written entirely in kana or
+// in Hepburn/Kunrei romaji (half- or full-width), and false otherwise.
+// Romaji is read as written, so long vowels it leaves unmarked (as in
+// "Tokyo") are read short.
+func guessReading(s string) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+	if reading, ok := kanaToKatakana(s); ok {
+		return reading, true
+	}
+	return romajiToKatakana(s)
+}
+
+func kanaToKatakana(s string) (string, bool) {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'ぁ' && r <= 'ゖ':
+			b.WriteRune(r + 0x60)
+		case r >= 'ァ' && r <= 'ヺ', r == 'ー':
+			b.WriteRune(r)
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+var romaji = map[string]string{
+	"a": "ア", "i": "イ", "u": "ウ", "e": "エ", "o": "オ",
+	"ka": "カ", "ki": "キ", "ku": "ク", "ke": "ケ", "ko": "コ",
+	"ga": "ガ", "gi": "ギ", "gu": "グ", "ge": "ゲ", "go": "ゴ",
+	"sa": "サ", "si": "シ", "shi": "シ", "su": "ス", "se": "セ", "so": "ソ",
+	"za": "ザ", "zi": "ジ", "ji": "ジ", "zu": "ズ", "ze": "ゼ", "zo": "ゾ",
+	"ta": "タ", "ti": "チ", "chi": "チ", "tu": "ツ", "tsu": "ツ", "te": "テ", "to": "ト",
+	"da": "ダ", "di": "ヂ", "du": "ヅ", "de": "デ", "do": "ド",
+	"na": "ナ", "ni": "ニ", "nu": "ヌ", "ne": "ネ", "no": "ノ",
+	"ha": "ハ", "hi": "ヒ", "hu": "フ", "fu": "フ", "he": "ヘ", "ho": "ホ",
+	"ba": "バ", "bi": "ビ", "bu": "ブ", "be": "ベ", "bo": "ボ",
+	"pa": "パ", "pi": "ピ", "pu": "プ", "pe": "ペ", "po": "ポ",
+	"ma": "マ", "mi": "ミ", "mu": "ム", "me": "メ", "mo": "モ",
+	"ya": "ヤ", "yu": "ユ", "yo": "ヨ",
+	"ra": "ラ", "ri": "リ", "ru": "ル", "re": "レ", "ro": "ロ",
+	"wa": "ワ", "wo": "ヲ",
+	"kya": "キャ", "kyu": "キュ", "kyo": "キョ",
+	"gya": "ギャ", "gyu": "ギュ", "gyo": "ギョ",
+	"sya": "シャ", "syu": "シュ", "syo": "ショ", "sha": "シャ", "shu": "シュ", "sho": "ショ",
+	"zya": "ジャ", "zyu": "ジュ", "zyo": "ジョ", "ja": "ジャ", "ju": "ジュ", "jo": "ジョ",
+	"tya": "チャ", "tyu": "チュ", "tyo": "チョ", "cha": "チャ", "chu": "チュ", "cho": "チョ",
+	"nya": "ニャ", "nyu": "ニュ", "nyo": "ニョ",
+	"hya": "ヒャ", "hyu": "ヒュ", "hyo": "ヒョ",
+	"bya": "ビャ", "byu": "ビュ", "byo": "ビョ",
+	"pya": "ピャ", "pyu": "ピュ", "pyo": "ピョ",
+	"mya": "ミャ", "myu": "ミュ", "myo": "ミョ",
+	"rya": "リャ", "ryu": "リュ", "ryo": "リョ",
+}
+
+func romajiToKatakana(s string) (string, bool) {
+	// Fold full-width Latin letters to ASCII and lower-case them.
+	s = strings.Map(func(r rune) rune {
+		if r >= 'Ａ' && r <= 'Ｚ' || r >= 'ａ' && r <= 'ｚ' {
+			r -= 'Ａ' - 'A'
+		}
+		return unicode.ToLower(r)
+	}, s)
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < 'a' || c > 'z' {
+			return "", false
+		}
+		// Syllabic n: n not followed by a vowel or y. A doubled "nn"
+		// stands for a single ン unless the second n starts a syllable,
+		// as in "konnichi".
+		if c == 'n' && (i+1 == len(s) || !strings.ContainsRune("aiueoy", rune(s[i+1]))) {
+			b.WriteString("ン")
+			i++
+			if i < len(s) && s[i] == 'n' && (i+1 == len(s) || !strings.ContainsRune("aiueoy", rune(s[i+1]))) {
+				i++
+			}
+			continue
+		}
+		// Geminate consonant: "kk", "tt", "tch", ...
+		if i+1 < len(s) && !strings.ContainsRune("aiueon", rune(c)) && (s[i+1] == c || c == 't' && s[i+1] == 'c') {
+			b.WriteString("ッ")
+			i++
+			continue
+		}
+		matched := false
+		for n := 3; n > 0; n-- {
+			if i+n <= len(s) {
+				if kana, ok := romaji[s[i:i+n]]; ok {
+					b.WriteString(kana)
+					i += n
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			return "", false
+		}
+	}
+	return b.String(), true
+}
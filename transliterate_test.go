@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"testing"
+)
+
+func TestGuessReading(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+		ok       bool
+	}{
+		{"ぐーぐる", "グーグル", true},
+		{"ミチ", "ミチ", true},
+		{"sushi", "スシ", true},
+		{"ｋａｎｓｈａ", "カンシャ", true},
+		{"kitte", "キッテ", true},
+		{"shinbun", "シンブン", true},
+		{"konnichiha", "コンニチハ", true},
+		{"hello", "", false},
+		{"未知", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		output, ok := guessReading(test.in)
+		if output != test.expected || ok != test.ok {
+			t.Errorf("guessReading(%q): expected %q, %v got %q, %v", test.in, test.expected, test.ok, output, ok)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"fmt"
+)
+
+// ViolationKind classifies a Violation.
+type ViolationKind int
+
+const (
+	BadChunkId     ViolationKind = iota // Id differs from the chunk's position
+	DanglingLink                        // Link points to no chunk
+	NoRoot                              // no chunk has Link == -1
+	MultipleRoots                       // more than one chunk has Link == -1
+	NotHeadFinal                        // a chunk depends on itself or a preceding chunk
+	NonProjective                       // two dependencies cross
+	EmptyChunk                          // a chunk has no tokens
+	HeadOutOfRange                      // Head is not an index into Tokens
+	FuncOutOfRange                      // Tail is not an index into Tokens
+)
+
+var violationKinds = [...]string{
+	BadChunkId:     "bad chunk id",
+	DanglingLink:   "dangling link",
+	NoRoot:         "no root",
+	MultipleRoots:  "multiple roots",
+	NotHeadFinal:   "not head-final",
+	NonProjective:  "non-projective",
+	EmptyChunk:     "empty chunk",
+	HeadOutOfRange: "head out of range",
+	FuncOutOfRange: "func out of range",
+}
+
+func (k ViolationKind) String() string {
+	if k < 0 || int(k) >= len(violationKinds) {
+		return fmt.Sprintf("ViolationKind(%d)", int(k))
+	}
+	return violationKinds[k]
+}
+
+// Violation is a well-formedness problem found by Sentence.Validate.
+type Violation struct {
+	Kind    ViolationKind
+	Chunk   int // index into Sentence.Chunks, or -1 for the whole sentence
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Kind.String() + ": " + v.Message
+}
+
+// Validate checks that s is a well-formed CaboCha dependency tree: chunk
+// ids match their positions, every link points to an existing chunk, there
+// is exactly one root and it is the last chunk, every other chunk depends
+// on a following one (Japanese is head-final), no two dependencies cross,
+// and Head and Tail index into each chunk's tokens.
+func (s *Sentence) Validate() []Violation {
+	var violations []Violation
+	add := func(kind ViolationKind, chunk int, format string, a ...interface{}) {
+		violations = append(violations, Violation{kind, chunk, fmt.Sprintf(format, a...)})
+	}
+
+	n := int64(len(s.Chunks))
+	var roots []int
+	for i, c := range s.Chunks {
+		if c.Id != int64(i) {
+			add(BadChunkId, i, "chunk %d has id %d", i, c.Id)
+		}
+		switch {
+		case c.Link == -1:
+			roots = append(roots, i)
+			if i != len(s.Chunks)-1 {
+				add(NotHeadFinal, i, "root chunk %d is not the last chunk", i)
+			}
+		case c.Link < 0 || c.Link >= n:
+			add(DanglingLink, i, "chunk %d links to nonexistent chunk %d", i, c.Link)
+		case c.Link <= int64(i):
+			add(NotHeadFinal, i, "chunk %d links back to chunk %d", i, c.Link)
+		}
+		if len(c.Tokens) == 0 {
+			add(EmptyChunk, i, "chunk %d has no tokens", i)
+			continue
+		}
+		if c.HeadToken() == nil {
+			add(HeadOutOfRange, i, "chunk %d has head %d but %d tokens", i, c.Head, len(c.Tokens))
+		}
+		if c.FuncToken() == nil {
+			add(FuncOutOfRange, i, "chunk %d has func %d but %d tokens", i, c.Tail, len(c.Tokens))
+		}
+	}
+	if len(s.Chunks) > 0 {
+		switch {
+		case len(roots) == 0:
+			add(NoRoot, -1, "no chunk has link -1")
+		case len(roots) > 1:
+			add(MultipleRoots, -1, "chunks %v all have link -1", roots)
+		}
+	}
+
+	// Dependencies (i, link) and (j, link) cross if exactly one endpoint
+	// of one lies strictly inside the other.
+	for i, a := range s.Chunks {
+		if a.Link < 0 || a.Link >= n {
+			continue
+		}
+		aMin, aMax := minMax(int64(i), a.Link)
+		for j := i + 1; j < len(s.Chunks); j++ {
+			b := s.Chunks[j]
+			if b.Link < 0 || b.Link >= n {
+				continue
+			}
+			bMin, bMax := minMax(int64(j), b.Link)
+			if aMin < bMin && bMin < aMax && aMax < bMax || bMin < aMin && aMin < bMax && bMax < aMax {
+				add(NonProjective, j, "dependency %d->%d crosses %d->%d", j, b.Link, i, a.Link)
+			}
+		}
+	}
+	return violations
+}
+
+func minMax(a, b int64) (int64, int64) {
+	if a < b {
+		return a, b
+	}
+	return b, a
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func kindsOf(vs []Violation) []ViolationKind {
+	kinds := []ViolationKind{}
+	for _, v := range vs {
+		kinds = append(kinds, v.Kind)
+	}
+	return kinds
+}
+
+func TestValidate(t *testing.T) {
+	if vs := NewSentence(latticeTree).Validate(); len(vs) != 0 {
+		t.Errorf("expected no violations, got %v", vs)
+	}
+	if vs := new(Sentence).Validate(); len(vs) != 0 {
+		t.Errorf("expected no violations for an empty sentence, got %v", vs)
+	}
+
+	cases := []struct {
+		name  string
+		edit  func(s *Sentence)
+		kind  ViolationKind
+		chunk int
+	}{
+		{"bad id", func(s *Sentence) { s.Chunks[2].Id = 7 }, BadChunkId, 2},
+		{"dangling", func(s *Sentence) { s.Chunks[4].Link = 9 }, DanglingLink, 4},
+		{"no root", func(s *Sentence) { s.Chunks[5].Link = 9 }, NoRoot, -1},
+		{"multiple roots", func(s *Sentence) { s.Chunks[1].Link = -1 }, MultipleRoots, -1},
+		{"backwards", func(s *Sentence) { s.Chunks[3].Link = 1 }, NotHeadFinal, 3},
+		{"crossing", func(s *Sentence) { s.Chunks[2].Link = 4 }, NonProjective, 3},
+		{"empty", func(s *Sentence) { s.Chunks[3].Tokens = nil }, EmptyChunk, 3},
+		{"head", func(s *Sentence) { s.Chunks[0].Head = 2 }, HeadOutOfRange, 0},
+		{"func", func(s *Sentence) { s.Chunks[0].Tail = -1 }, FuncOutOfRange, 0},
+	}
+	for _, c := range cases {
+		s := NewSentence(latticeTree)
+		c.edit(s)
+		found := false
+		for _, v := range s.Validate() {
+			if v.Kind == c.kind && v.Chunk == c.chunk {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected %v at chunk %d, got %v", c.name, c.kind, c.chunk, kindsOf(s.Validate()))
+		}
+	}
+}
+
+func TestDecoderStrictValidate(t *testing.T) {
+	// Chunk 2 links back to chunk 1, on line 7.
+	input := strings.Replace(latticeTree, "* 2 3D", "* 2 1D", 1)
+
+	if _, err := DecodeSentence(input, DecodeOptions{}); err != nil {
+		t.Errorf("lenient: unexpected error %v", err)
+	}
+
+	_, err := DecodeSentence(input, DecodeOptions{Strict: true})
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 7 {
+		t.Fatalf("DecodeSentence: expected error at line 7, got %v", err)
+	}
+
+	d := NewDecoderWithOptions(strings.NewReader(input), DecodeOptions{Strict: true})
+	_, err = d.Next()
+	if !errors.As(err, &de) || de.Line != 7 {
+		t.Errorf("Decoder: expected error at line 7, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Returns s as the structure of CaboCha's XML output. Token ids count
+// from the start of the sentence.
+func (s *Sentence) toSentenceXML() *SentenceXML {
+	sx := new(SentenceXML)
+	id := 0
+	for _, c := range s.Chunks {
+		cx := &ChunkXML{Id: c.Id, Link: c.Link, Rel: "D", Prob: c.Prob, Head: c.Head, Tail: c.Tail}
+		for _, t := range c.Tokens {
+			cx.Tokens = append(cx.Tokens, &TokenXML{
+				Id:       id,
+				Features: joinFeatures(t.featureList()),
+				Ne:       t.Ne,
+				Orth:     t.Surface(),
+			})
+			id++
+		}
+		sx.Chunks = append(sx.Chunks, cx)
+	}
+	return sx
+}
+
+var xmlEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// Writes sx the way CaboCha does: one element per line, indented by one
+// space per level, with scores to six decimal places.
+func (sx *SentenceXML) write(b *bytes.Buffer) {
+	b.WriteString("<sentence>\n")
+	for _, c := range sx.Chunks {
+		fmt.Fprintf(b, " <chunk id=\"%d\" link=\"%d\" rel=\"%s\" score=\"%f\" head=\"%d\" func=\"%d\">\n",
+			c.Id, c.Link, xmlEscaper.Replace(c.Rel), c.Prob, c.Head, c.Tail)
+		for _, t := range c.Tokens {
+			fmt.Fprintf(b, "  <tok id=\"%d\" feature=\"%s\"", t.Id, xmlEscaper.Replace(t.Features))
+			if t.Ne != "" {
+				fmt.Fprintf(b, " ne=\"%s\"", xmlEscaper.Replace(t.Ne))
+			}
+			fmt.Fprintf(b, ">%s</tok>\n", xmlEscaper.Replace(t.Orth))
+		}
+		b.WriteString(" </chunk>\n")
+	}
+	b.WriteString("</sentence>\n")
+}
+
+// MarshalXML encodes s as a CaboCha <sentence> element, whatever the name
+// of start. Use ToXML for CaboCha's exact layout.
+func (s Sentence) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(s.toSentenceXML())
+}
+
+// UnmarshalXML decodes a <sentence> element of CaboCha's XML output (-f3),
+// as NewSentence does for lattice output.
+func (s *Sentence) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var sx SentenceXML
+	if err := d.DecodeElement(&sx, &start); err != nil {
+		return err
+	}
+	b := new(sentenceBuilder)
+	for _, cx := range sx.Chunks {
+		b.flush()
+		b.c = &Chunk{Id: cx.Id, Link: cx.Link, Prob: cx.Prob, Head: cx.Head, Tail: cx.Tail}
+		for _, tx := range cx.Tokens {
+			features, err := splitFeatures(tx.Features, true)
+			if err != nil {
+				return &DecodeError{Reason: fmt.Sprintf("malformed feature attribute of token %d: %v", tx.Id, err)}
+			}
+			b.addToken(tx.Orth, features, tx.Ne)
+		}
+	}
+	*s = *b.finish(DecodeOptions{})
+	s.Resolve()
+	return nil
+}
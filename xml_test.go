@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2012 Bor Hodošček. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package natsume_cabocha_bindings
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"testing"
+)
+
+// cabocha -f3 output for outputCorrect and latticeEscapedQuote.
+var outputCorrectXML = `<sentence>
+ <chunk id="0" link="-1" rel="D" score="0.000000" head="3" func="3">
+  <tok id="0" feature="名詞,普通名詞,一般,*,*,*" ne="O">hello</tok>
+  <tok id="1" feature="補助記号,読点,*,*,*,*,,，,，,,,記号,，,,,,*,*,*,*,*,*,*,*,*" ne="O">，</tok>
+  <tok id="2" feature="名詞,普通名詞,形状詞可能,*,*,*,ミチ,未知,未知,ミチ,ミチ,漢,未知,ミチ,ミチ,ミチ,*,*,*,*,*,*,1,C3,*" ne="O">未知</tok>
+  <tok id="3" feature="名詞,普通名詞,一般,*,*,*,ゴ,語,語,ゴ,ゴ,漢,語,ゴ,ゴ,ゴ,*,*,*,*,*,*,1,C3,*" ne="O">語</tok>
+ </chunk>
+</sentence>
+`
+
+var latticeEscapedQuoteXML = `<sentence>
+ <chunk id="0" link="-1" rel="D" score="1.316291" head="0" func="0">
+  <tok id="0" feature="補助記号,括弧開,*,*,*,*,,&quot;&quot;&quot;&quot;,&quot;&quot;&quot;&quot;,,,記号,&quot;&quot;&quot;&quot;,,,,*,*,*,*,*,*,*,*,*" ne="O">&quot;</tok>
+ </chunk>
+</sentence>
+`
+
+func TestToXML(t *testing.T) {
+	for lattice, expected := range map[string]string{
+		outputCorrect:       outputCorrectXML,
+		latticeEscapedQuote: latticeEscapedQuoteXML,
+		"EOS\n":             "<sentence>\n</sentence>\n",
+	} {
+		if output := string(NewSentence(lattice).ToXML()); output != expected {
+			t.Errorf("Echo: expected %q got %q", expected, output)
+		}
+	}
+}
+
+func TestUnmarshalXML(t *testing.T) {
+	for lattice, input := range map[string]string{
+		outputCorrect:       outputCorrectXML,
+		latticeEscapedQuote: latticeEscapedQuoteXML,
+	} {
+		var s Sentence
+		if err := xml.Unmarshal([]byte(input), &s); err != nil {
+			t.Fatal(err)
+		}
+		if output := s.ToLattice(); output != lattice {
+			t.Errorf("Echo: expected %q got %q", lattice, output)
+		}
+		if s.Chunks[0].Parent() != nil || s.Chunks[0].sentence != &s {
+			t.Errorf("chunks not resolved")
+		}
+	}
+
+	var s Sentence
+	xml.Unmarshal([]byte(outputCorrectXML), &s)
+	if output := s.ToJSON(); !bytes.Equal(output, outputCorrectJSON) {
+		t.Errorf("Echo: expected %q got %q", outputCorrectJSON, output)
+	}
+}
+
+func TestMarshalXML(t *testing.T) {
+	output, err := xml.Marshal(NewSentence(latticeTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Sentence
+	if err := xml.Unmarshal(output, &s); err != nil {
+		t.Fatal(err)
+	}
+	if lattice := s.ToLattice(); lattice != latticeTree {
+		t.Errorf("Echo: expected %q got %q", latticeTree, lattice)
+	}
+}
+
+func TestParseToXML(t *testing.T) {
+	p, err := NewParser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	expected, err := p.ParseToFormat(input, FormatXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := p.Parse(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := string(s.ToXML()); output != expected {
+		t.Errorf("Echo: expected %q got %q", expected, output)
+	}
+}